@@ -16,16 +16,13 @@ package dleq
 
 import (
 	"crypto"
-	"errors"
+	crand "crypto/rand"
+	"io"
 	"math/big"
 
 	"golang.org/x/crypto/sha3"
 )
 
-var (
-	ErrUnequalPointCounts = errors.New("batch proof had unequal numbers of points")
-)
-
 type BatchProof struct {
 	P    *Proof
 	G, H *Point
@@ -34,11 +31,62 @@ type BatchProof struct {
 }
 
 func NewBatchProof(hash crypto.Hash, g, h *Point, m []*Point, z []*Point, x *big.Int) (*BatchProof, error) {
+	return newBatchProof(hash, g, h, m, z, x, crand.Reader)
+}
+
+// newBatchProof is NewBatchProof with the nonce read from rand.
+func newBatchProof(hash crypto.Hash, g, h *Point, m []*Point, z []*Point, x *big.Int, rand io.Reader) (*BatchProof, error) {
 	if len(m) != len(z) {
 		return nil, ErrUnequalPointCounts
 	}
+	if !hash.Available() {
+		return nil, ErrUnknownHash
+	}
+	// The points have to be checked before composite multiplies them, since
+	// crypto/elliptic panics on points off the curve.
+	if err := checkBatchPoints(g, h, m, z); err != nil {
+		return nil, err
+	}
+
+	compositeM, compositeZ, C, err := composite(hash, g, h, m, z)
+	if err != nil {
+		return nil, err
+	}
+
+	proof, err := NewProofWithReader(hash, g, h, compositeM, compositeZ, x, rand)
+	if err != nil {
+		return nil, err
+	}
+	return &BatchProof{
+		P: proof,
+		G: g, H: h,
+		M: m, Z: z,
+		C: C,
+	}, nil
+}
 
-	// The underlying proof and validation steps will do consistency checks.
+// checkBatchPoints checks that g, h and every m[i] and z[i] are set, on the
+// same curve, and on that curve.
+func checkBatchPoints(g, h *Point, m []*Point, z []*Point) error {
+	points := append([]*Point{g, h}, m...)
+	points = append(points, z...)
+	for _, p := range points {
+		if p == nil || p.Curve == nil || p.X == nil || p.Y == nil {
+			return ErrPointOffCurve
+		}
+		if !SameCurve(p.Curve, g.Curve) {
+			return ErrInconsistentCurves
+		}
+		if !p.IsOnCurve() {
+			return ErrPointOffCurve
+		}
+	}
+	return nil
+}
+
+// composite derives the coefficients c_i from the batch and combines the
+// m and z elements into the two composite points the proof is about.
+func composite(hash crypto.Hash, g, h *Point, m []*Point, z []*Point) (*Point, *Point, [][]byte, error) {
 	curve := g.Curve
 
 	// seed = H(g, h, [m], [z])
@@ -70,7 +118,7 @@ func NewBatchProof(hash crypto.Hash, g, h *Point, m []*Point, z []*Point, x *big
 	for i := 0; i < len(m); i++ {
 		ci, _, err := randScalar(curve, prng)
 		if err != nil {
-			return nil, err
+			return nil, nil, nil, err
 		}
 		// cM = c[i]M[i]
 		cMx, cMy := curve.ScalarMult(m[i].X, m[i].Y, ci)
@@ -83,17 +131,7 @@ func NewBatchProof(hash crypto.Hash, g, h *Point, m []*Point, z []*Point, x *big
 	}
 	compositeM := &Point{Curve: curve, X: Mx, Y: My}
 	compositeZ := &Point{Curve: curve, X: Zx, Y: Zy}
-
-	proof, err := NewProof(hash, g, h, compositeM, compositeZ, x)
-	if err != nil {
-		return nil, err
-	}
-	return &BatchProof{
-		P: proof,
-		G: g, H: h,
-		M: m, Z: z,
-		C: C,
-	}, nil
+	return compositeM, compositeZ, C, nil
 }
 
 func (b *BatchProof) IsComplete() bool {
@@ -106,11 +144,11 @@ func (b *BatchProof) IsSane() bool {
 	if len(b.M) != len(b.Z) {
 		return false
 	}
-	if b.G.Curve != b.H.Curve {
+	if !SameCurve(b.G.Curve, b.H.Curve) {
 		return false
 	}
 	for i := 0; i < len(b.M); i++ {
-		if b.G.Curve != b.M[i].Curve || b.G.Curve != b.Z[i].Curve {
+		if !SameCurve(b.G.Curve, b.M[i].Curve) || !SameCurve(b.G.Curve, b.Z[i].Curve) {
 			return false
 		}
 		if !b.M[i].IsOnCurve() || !b.Z[i].IsOnCurve() {
@@ -120,9 +158,50 @@ func (b *BatchProof) IsSane() bool {
 	return true
 }
 
-func (b *BatchProof) Verify() bool {
-	if !b.IsComplete() || !b.IsSane() {
+// isAbout reports whether the proof's batch is exactly (g, h, [m], [z]).
+func (b *BatchProof) isAbout(g, h *Point, m, z []*Point) bool {
+	if b.G == nil || b.H == nil || !b.G.equal(g) || !b.H.equal(h) {
+		return false
+	}
+	if len(m) != len(z) || len(b.M) != len(m) || len(b.Z) != len(z) {
 		return false
 	}
-	return b.P.Verify()
+	for i := range m {
+		if !b.M[i].equal(m[i]) || !b.Z[i].equal(z[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func (b *BatchProof) Verify() bool {
+	return b.VerifyError() == nil
+}
+
+// VerifyError is Verify reporting why the proof was rejected, as
+// Proof.VerifyError does.
+func (b *BatchProof) VerifyError() error {
+	if !b.IsComplete() || b.P == nil || !b.P.IsComplete() {
+		return ErrIncompleteProof
+	}
+	if len(b.M) != len(b.Z) {
+		return ErrUnequalPointCounts
+	}
+	if !b.IsSane() || !b.P.IsSane() {
+		return ErrPointOffCurve
+	}
+	if !b.P.hash.Available() {
+		return ErrUnknownHash
+	}
+
+	// The inner proof is only about the composite points, so it says
+	// nothing about the batch unless they're recomputed from it here.
+	compositeM, compositeZ, _, err := composite(b.P.hash, b.G, b.H, b.M, b.Z)
+	if err != nil {
+		return err
+	}
+	if !b.P.G.equal(b.G) || !b.P.H.equal(b.H) || !b.P.M.equal(compositeM) || !b.P.Z.equal(compositeZ) {
+		return ErrStatementMismatch
+	}
+	return b.P.VerifyError()
 }
@@ -88,3 +88,18 @@ func TestInvalidBatchProof(t *testing.T) {
 		t.Fatal("verified an invalid batch proof")
 	}
 }
+
+func TestBatchProofBindsBatch(t *testing.T) {
+	// The inner proof is valid on its own, so replacing the batch it was
+	// made for must be caught by recomputing the composite points.
+	G, Y, k, tokens, signed := testIssuance(t, 10)
+	proof, err := NewBatchProof(crypto.SHA256, G, Y, tokens, signed, k)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _, _, otherTokens, otherSigned := testIssuance(t, 10)
+	proof.M, proof.Z = otherTokens, otherSigned
+	if proof.Verify() {
+		t.Fatal("verified a batch proof over a substituted batch")
+	}
+}
@@ -0,0 +1,48 @@
+package dleq
+
+import (
+	"bytes"
+	"crypto"
+	crand "crypto/rand"
+	"encoding/binary"
+	"math/big"
+)
+
+// A Beacon is a round of a public randomness beacon, in the style of drand.
+type Beacon struct {
+	Round uint64
+	Value []byte
+}
+
+func (b *Beacon) bytes() []byte {
+	out := binary.BigEndian.AppendUint64(nil, b.Round)
+	return append(out, b.Value...)
+}
+
+// NewProofWithBeacon proves statement with a beacon round and its value
+// folded into the transcript. As long as the value was unpredictable before
+// the round, the proof can't have been made before it. The beacon is kept on
+// the proof and in its serialized forms, so Verify checks it as is; use
+// VerifyBeacon to also require a particular round.
+func NewProofWithBeacon(hash crypto.Hash, statement *Statement, x *big.Int, round uint64, beaconValue []byte) (*Proof, error) {
+	proof := newProof(hash, statement, nil)
+	proof.beacon = &Beacon{Round: round, Value: append([]byte{}, beaconValue...)}
+	if _, _, err := proof.prove(x, crand.Reader); err != nil {
+		return nil, err
+	}
+	return proof, nil
+}
+
+// Beacon returns the beacon the proof is anchored to, or nil.
+func (pr *Proof) Beacon() *Beacon {
+	return pr.beacon
+}
+
+// VerifyBeacon verifies the proof and that it is anchored to the given
+// beacon round and value.
+func (pr *Proof) VerifyBeacon(round uint64, beaconValue []byte) bool {
+	if pr.beacon == nil || pr.beacon.Round != round || !bytes.Equal(pr.beacon.Value, beaconValue) {
+		return false
+	}
+	return pr.Verify()
+}
@@ -0,0 +1,69 @@
+package dleq
+
+import (
+	"crypto"
+	"crypto/elliptic"
+	"encoding/json"
+	"testing"
+)
+
+func TestProofWithBeacon(t *testing.T) {
+	G, H, M, Z, x := testStatement(t, elliptic.P256())
+	value := []byte("round 1000 randomness")
+	proof, err := NewProofWithBeacon(crypto.SHA256, &Statement{G: G, H: H, M: M, Z: Z}, x, 1000, value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !proof.Verify() || !proof.VerifyBeacon(1000, value) {
+		t.Fatal("proof was invalid")
+	}
+	if proof.VerifyBeacon(1001, value) {
+		t.Fatal("proof verified under a different round")
+	}
+	if proof.VerifyBeacon(1000, []byte("other randomness")) {
+		t.Fatal("proof verified under a different value")
+	}
+
+	// Swapping the stored beacon breaks the transcript.
+	forged := *proof
+	forged.beacon = &Beacon{Round: 1001, Value: value}
+	if forged.Verify() {
+		t.Fatal("proof verified after its beacon was replaced")
+	}
+}
+
+func TestProofWithBeaconSerialization(t *testing.T) {
+	G, H, M, Z, x := testStatement(t, elliptic.P256())
+	value := []byte("round 1000 randomness")
+	proof, err := NewProofWithBeacon(crypto.SHA256, &Statement{G: G, H: H, M: M, Z: Z}, x, 1000, value)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := proof.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded := new(Proof)
+	if err := decoded.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if !decoded.VerifyBeacon(1000, value) {
+		t.Fatal("binary round trip lost the beacon")
+	}
+
+	data, err = json.Marshal(proof)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ValidateJSON(data); err != nil {
+		t.Fatal(err)
+	}
+	decoded = new(Proof)
+	if err := json.Unmarshal(data, decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !decoded.VerifyBeacon(1000, value) {
+		t.Fatal("JSON round trip lost the beacon")
+	}
+}
@@ -0,0 +1,73 @@
+package dleq
+
+import (
+	"crypto"
+	"crypto/elliptic"
+	"math/big"
+)
+
+// VerifyBytes verifies a proof given as raw bytes, for services that
+// receive the statement off the wire and only need the verdict. The points
+// g, h, m and z are SEC 1 encoded on curve, in any of the encodings their
+// leading byte announces, and c and r are big-endian scalars padded to
+// ScalarLen(curve). Malformed inputs are reported as a *FieldError naming
+// the offending argument; otherwise the error is nil and the bool is the
+// verdict.
+func VerifyBytes(curve elliptic.Curve, hash crypto.Hash, g, h, m, z []byte, c, r []byte) (bool, error) {
+	if curve == nil {
+		return false, ErrUnknownCurve
+	}
+	if !hash.Available() {
+		return false, ErrUnknownHash
+	}
+	points := make([]*Point, 4)
+	for i, field := range []struct {
+		name string
+		data []byte
+	}{{"g", g}, {"h", h}, {"m", m}, {"z", z}} {
+		p, err := decodePoint(curve, field.data)
+		if err != nil {
+			return false, &FieldError{Field: field.name, Err: err}
+		}
+		points[i] = p
+	}
+	scalars := make([]*big.Int, 2)
+	for i, field := range []struct {
+		name string
+		data []byte
+	}{{"c", c}, {"r", r}} {
+		if len(field.data) != ScalarLen(curve) {
+			return false, &FieldError{Field: field.name, Err: ErrFieldLength}
+		}
+		scalars[i] = new(big.Int).SetBytes(field.data)
+		if !scalarInRange(curve, scalars[i]) {
+			return false, &FieldError{Field: field.name, Err: ErrScalarRange}
+		}
+	}
+
+	pr := &Proof{
+		G: points[0], H: points[1], M: points[2], Z: points[3],
+		C: scalars[0], R: scalars[1],
+		params: params{hash: hash},
+	}
+	return pr.Verify(), nil
+}
+
+// decodePoint decodes a SEC 1 point on curve with Point.Unmarshal, once it
+// has the length of a point on curve. A wrong length is ErrFieldLength.
+func decodePoint(curve elliptic.Curve, data []byte) (*Point, error) {
+	if !pointLength(curve, len(data)) {
+		return nil, ErrFieldLength
+	}
+	p := new(Point)
+	if err := p.Unmarshal(curve, data); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// pointLength reports whether n is the length of an encoded point on curve.
+// Hybrid points are as long as uncompressed ones.
+func pointLength(curve elliptic.Curve, n int) bool {
+	return n == Uncompressed.encodedLen(curve) || n == Compressed.encodedLen(curve)
+}
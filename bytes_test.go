@@ -0,0 +1,66 @@
+package dleq
+
+import (
+	"crypto"
+	"crypto/elliptic"
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func TestVerifyBytes(t *testing.T) {
+	curve := elliptic.P256()
+	G, H, M, Z, x := testStatement(t, curve)
+	valid, err := NewProof(crypto.SHA256, G, H, M, Z, x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	invalid := *valid
+	invalid.R = new(big.Int).Add(valid.R, big.NewInt(1))
+
+	compressed := func(p *Point) []byte {
+		data, err := p.MarshalEncoding(Compressed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return data
+	}
+	for _, pr := range []*Proof{valid, &invalid} {
+		got, err := VerifyBytes(curve, crypto.SHA256,
+			pr.G.Marshal(), compressed(pr.H), pr.M.Marshal(), compressed(pr.Z),
+			scalarBytes(curve, pr.C), scalarBytes(curve, pr.R))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != pr.Verify() {
+			t.Fatalf("VerifyBytes said %v, Verify said %v", got, pr.Verify())
+		}
+	}
+
+	g, h, m, z := G.Marshal(), H.Marshal(), M.Marshal(), Z.Marshal()
+	c, r := scalarBytes(curve, valid.C), scalarBytes(curve, valid.R)
+	offCurve := append([]byte{}, h...)
+	offCurve[len(offCurve)-1] ^= 1
+	for _, tc := range []struct {
+		field      string
+		err        error
+		g, h, m, z []byte
+		c, r       []byte
+	}{
+		{"g", ErrFieldLength, nil, h, m, z, c, r},
+		{"h", ErrInvalidPoint, g, offCurve, m, z, c, r},
+		{"m", ErrFieldLength, g, h, m[:40], z, c, r},
+		{"z", ErrUnknownEncoding, g, h, m, append([]byte{0x05}, z[1:]...), c, r},
+		{"c", ErrFieldLength, g, h, m, z, c[1:], r},
+		{"r", ErrScalarRange, g, h, m, z, c, curve.Params().N.Bytes()},
+	} {
+		_, err := VerifyBytes(curve, crypto.SHA256, tc.g, tc.h, tc.m, tc.z, tc.c, tc.r)
+		var fe *FieldError
+		if !errors.As(err, &fe) || fe.Field != tc.field || fe.Err != tc.err {
+			t.Errorf("%s: expected a FieldError with %v, got %v", tc.field, tc.err, err)
+		}
+	}
+	if _, err := VerifyBytes(curve, crypto.MD4, g, h, m, z, c, r); err != ErrUnknownHash {
+		t.Fatalf("expected ErrUnknownHash, got %v", err)
+	}
+}
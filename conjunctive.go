@@ -0,0 +1,153 @@
+package dleq
+
+import (
+	"crypto"
+	crand "crypto/rand"
+	"math/big"
+)
+
+// A ConjunctiveProof shows that one witness x satisfies several statements
+// at once, log_Gi(Hi) == log_Mi(Zi) == x for every i, with a single
+// challenge and response. The statements share the curve but need not share
+// generators.
+//
+// The transcript is that of Proof extended to every statement: all of the
+// statements' points in turn, then all of the commitments. A conjunction of
+// one statement is exactly a Proof.
+type ConjunctiveProof struct {
+	Statements []*Statement
+	C, R       *big.Int
+
+	params
+}
+
+// NewConjunctiveProof proves all of statements with the witness x.
+func NewConjunctiveProof(hash crypto.Hash, statements []*Statement, x *big.Int) (*ConjunctiveProof, error) {
+	if len(statements) == 0 {
+		return nil, ErrIncompleteProof
+	}
+	if err := checkConjunction(statements); err != nil {
+		return nil, err
+	}
+	if !hash.Available() {
+		return nil, ErrUnknownHash
+	}
+	curve := statements[0].G.Curve
+
+	// One nonce s for every statement, so that one response answers the
+	// shared challenge: (ai, bi) = (sGi, sMi).
+	s := new(big.Int)
+	sBytes, a0, b0, err := commitInto(curve, statements[0].G, statements[0].M, crand.Reader, nil, s)
+	if err != nil {
+		return nil, err
+	}
+	commitments := []*Point{a0, b0}
+	for _, stmt := range statements[1:] {
+		Ax, Ay := curve.ScalarMult(stmt.G.X, stmt.G.Y, sBytes)
+		Bx, By := curve.ScalarMult(stmt.M.X, stmt.M.Y, sBytes)
+		commitments = append(commitments, &Point{Curve: curve, X: Ax, Y: Ay}, &Point{Curve: curve, X: Bx, Y: By})
+	}
+
+	cp := &ConjunctiveProof{
+		Statements: append([]*Statement{}, statements...),
+		params:     params{hash: hash},
+	}
+	cp.C = cp.challenge(commitments)
+	cp.R = ComputeResponse(s, cp.C, x, curve.Params().N)
+	return cp, nil
+}
+
+// MergeProofs replaces two proofs about the same witness with one
+// ConjunctiveProof of both statements, using p1's hash.
+//
+// Two independent proofs can't be merged as they stand: each answers its
+// own challenge with its own nonce, and no combination of (C1, R1) and
+// (C2, R2) answers a shared challenge without the witness. So this is a
+// prover-side helper that proves both statements again under one
+// challenge. It fails with ErrWrongWitness unless x is the witness of both
+// statements; the proofs themselves are not verified.
+func MergeProofs(p1, p2 *Proof, x *big.Int) (*ConjunctiveProof, error) {
+	if !p1.IsComplete() || !p2.IsComplete() {
+		return nil, ErrIncompleteProof
+	}
+	statements := []*Statement{p1.Statement(), p2.Statement()}
+	if err := checkConjunction(statements); err != nil {
+		return nil, err
+	}
+	for _, stmt := range statements {
+		if !stmt.holdsFor(x) {
+			return nil, ErrWrongWitness
+		}
+	}
+	return NewConjunctiveProof(p1.hash, statements, x)
+}
+
+// checkConjunction checks each statement and that they share a curve.
+func checkConjunction(statements []*Statement) error {
+	for _, stmt := range statements {
+		if stmt == nil {
+			return ErrIncompleteProof
+		}
+		if err := stmt.check(); err != nil {
+			return err
+		}
+		if !SameCurve(stmt.G.Curve, statements[0].G.Curve) {
+			return ErrInconsistentCurves
+		}
+	}
+	return nil
+}
+
+func (cp *ConjunctiveProof) challenge(commitments []*Point) *big.Int {
+	return statementsChallenge(cp.hash, cp.Statements, commitments)
+}
+
+// statementsChallenge hashes every statement and then every commitment,
+// given in statement order as a1, b1, a2, b2, ...
+func statementsChallenge(hash crypto.Hash, statements []*Statement, commitments []*Point) *big.Int {
+	H := hash.New()
+	for _, stmt := range statements {
+		for _, p := range []*Point{stmt.G, stmt.H, stmt.M, stmt.Z} {
+			H.Write(p.Marshal())
+		}
+	}
+	for _, p := range commitments {
+		H.Write(p.Marshal())
+	}
+	c := new(big.Int).SetBytes(H.Sum(nil))
+	return c.Mod(c, statements[0].G.Curve.Params().N)
+}
+
+func (cp *ConjunctiveProof) Verify() bool {
+	return cp.VerifyError() == nil
+}
+
+// VerifyError is Verify reporting why the proof was rejected, as
+// Proof.VerifyError does.
+func (cp *ConjunctiveProof) VerifyError() error {
+	if len(cp.Statements) == 0 || cp.C == nil || cp.R == nil {
+		return ErrIncompleteProof
+	}
+	if err := checkConjunction(cp.Statements); err != nil {
+		return err
+	}
+	if !cp.hash.Available() {
+		return ErrUnknownHash
+	}
+
+	// The commitments of each statement are those of a Proof with the
+	// shared (C, R), which also does the range checks.
+	var commitments []*Point
+	for _, stmt := range cp.Statements {
+		pr := &Proof{G: stmt.G, H: stmt.H, M: stmt.M, Z: stmt.Z, C: cp.C, R: cp.R, params: cp.params}
+		if err := pr.checkScalars(); err != nil {
+			return err
+		}
+		a, b := pr.commitments()
+		commitments = append(commitments, a, b)
+	}
+	if !scalarEqual(cp.Statements[0].G.Curve, cp.C, cp.challenge(commitments)) {
+		return ErrInvalidProof
+	}
+	return nil
+}
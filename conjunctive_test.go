@@ -0,0 +1,78 @@
+package dleq
+
+import (
+	"crypto"
+	"crypto/elliptic"
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func TestMergeProofs(t *testing.T) {
+	curve := elliptic.P256()
+	G, H, M, Z, x := testStatement(t, curve)
+	G2, _, M2, _, _ := testStatement(t, curve)
+	H2, Z2 := mustPublicKey(t, G2, x), mustPublicKey(t, M2, x)
+
+	p1, err := NewProof(crypto.SHA256, G, H, M, Z, x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p2, err := NewProof(crypto.SHA256, G2, H2, M2, Z2, x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	merged, err := MergeProofs(p1, p2, x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !merged.Verify() {
+		t.Fatal("merged proof was invalid")
+	}
+
+	// A verifier who knows the statements needs only the scalars, one
+	// pair for the merged proof against a pair per separate proof.
+	size := func(scalars ...*big.Int) (n int) {
+		for _, k := range scalars {
+			n += len(scalarBytes(curve, k))
+		}
+		return n
+	}
+	if merged, separate := size(merged.C, merged.R), size(p1.C, p1.R, p2.C, p2.R); merged >= separate {
+		t.Fatalf("merged proof is %d bytes, separate proofs %d", merged, separate)
+	}
+
+	// Swapping in a statement the witness doesn't satisfy breaks it.
+	bad := *merged
+	bad.Statements = []*Statement{merged.Statements[0], {G: G2, H: H2, M: M2, Z: Z}}
+	if err := bad.VerifyError(); err != ErrInvalidProof {
+		t.Fatalf("expected ErrInvalidProof, got %v", err)
+	}
+
+	other := new(big.Int).Add(x, big.NewInt(1))
+	if _, err := MergeProofs(p1, p2, other); err != ErrWrongWitness {
+		t.Fatalf("expected ErrWrongWitness, got %v", err)
+	}
+	q, _, _, _, _ := testStatement(t, elliptic.P384())
+	p3 := *p2
+	p3.G = q
+	if _, err := MergeProofs(p1, &p3, x); !errors.Is(err, ErrInconsistentCurves) {
+		t.Fatalf("expected ErrInconsistentCurves, got %v", err)
+	}
+}
+
+func TestConjunctiveProofOfOne(t *testing.T) {
+	// One statement has Proof's transcript, so its (C, R) is a Proof.
+	G, H, M, Z, x := testStatement(t, elliptic.P256())
+	cp, err := NewConjunctiveProof(crypto.SHA256, []*Statement{{G: G, H: H, M: M, Z: Z}}, x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	proof := &Proof{G: G, H: H, M: M, Z: Z, C: cp.C, R: cp.R, params: params{hash: crypto.SHA256}}
+	if !cp.Verify() || !proof.Verify() {
+		t.Fatal("conjunction of one statement did not verify as a Proof")
+	}
+	if _, err := NewConjunctiveProof(crypto.SHA256, nil, x); err != ErrIncompleteProof {
+		t.Fatalf("expected ErrIncompleteProof, got %v", err)
+	}
+}
@@ -0,0 +1,81 @@
+package dleq
+
+import (
+	"crypto"
+	"crypto/elliptic"
+	"crypto/subtle"
+	"math/big"
+)
+
+// VerifyConstantTime is Verify for protocols in which the validity of a
+// proof is itself secret. Where Verify returns as soon as a check fails,
+// this always does the full computation: missing, off-curve and mismatched
+// points are swapped for the base point, out-of-range scalars for zero and
+// unavailable hashes or invalid options for the defaults, and the
+// challenge is compared in constant time. The swaps themselves branch, so
+// a malformed proof may take measurably different time; what doesn't
+// depend on the verdict is the work done on a well-formed proof.
+//
+// This is only as constant time as the curve. The P-224, P-256, P-384 and
+// P-521 implementations from crypto/elliptic are; custom curves built on
+// elliptic.CurveParams use generic big.Int arithmetic and are not. The
+// big.Int bookkeeping around the curve operations is also variable time in
+// the magnitude of the scalars, which are public.
+func (pr *Proof) VerifyConstantTime() bool {
+	valid := 1
+
+	curve := elliptic.P256()
+	if pr.G != nil && pr.G.Curve != nil {
+		curve = pr.G.Curve
+	}
+	params := curve.Params()
+	base := &Point{Curve: curve, X: params.Gx, Y: params.Gy}
+
+	point := func(p *Point) *Point {
+		if p == nil || p.Curve == nil || p.X == nil || p.Y == nil ||
+			!SameCurve(p.Curve, curve) || !p.IsOnCurve() {
+			valid = 0
+			return base
+		}
+		return p
+	}
+	scalar := func(k *big.Int) *big.Int {
+		if k == nil || !scalarInRange(curve, k) {
+			valid = 0
+			return new(big.Int)
+		}
+		return k
+	}
+
+	check := &Proof{
+		G: point(pr.G), M: point(pr.M),
+		H: point(pr.H), Z: point(pr.Z),
+		R: scalar(pr.R), C: scalar(pr.C),
+		params: pr.params,
+	}
+	// Unusable parameters are swapped for defaults too, so that the
+	// challenge is still computed.
+	if !check.hash.Available() {
+		valid = 0
+		check.hash = crypto.SHA256
+	}
+	if check.hash2 != 0 && !check.hash2.Available() {
+		valid = 0
+		check.hash2 = 0
+	}
+	if check.opts.checkOrder() != nil {
+		valid = 0
+		check.opts.TranscriptOrder = TranscriptGHMZ
+	}
+	if check.opts.checkChallengeBits(curve) != nil {
+		valid = 0
+		check.opts.ChallengeBits = 0
+	}
+	valid &= 1 - subtle.ConstantTimeEq(int32(check.C.Sign()), 0)
+
+	a, b := check.commitments()
+	c := check.challenge(a, b)
+
+	valid &= subtle.ConstantTimeCompare(scalarBytes(curve, check.C), scalarBytes(curve, c))
+	return valid == 1
+}
@@ -0,0 +1,64 @@
+package dleq
+
+import (
+	"crypto"
+	"crypto/elliptic"
+	"math/big"
+	"testing"
+)
+
+func TestVerifyConstantTime(t *testing.T) {
+	curve := elliptic.P256()
+	G, H, M, Z, x := testStatement(t, curve)
+	proof, err := NewProof(crypto.SHA256, G, H, M, Z, x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !proof.VerifyConstantTime() {
+		t.Fatal("proof was invalid")
+	}
+
+	offCurve := &Point{Curve: curve, X: new(big.Int).Set(H.X), Y: new(big.Int).Add(H.Y, big.NewInt(1))}
+	tests := []struct {
+		name   string
+		modify func(*Proof)
+	}{
+		{"wrong key", func(pr *Proof) { pr.Z = pr.H }},
+		{"off-curve point", func(pr *Proof) { pr.H = offCurve }},
+		{"missing response", func(pr *Proof) { pr.R = nil }},
+		{"missing generator", func(pr *Proof) { pr.M = nil }},
+		{"unreduced challenge", func(pr *Proof) { pr.C = new(big.Int).Add(pr.C, curve.Params().N) }},
+	}
+	for _, tt := range tests {
+		bad := *proof
+		tt.modify(&bad)
+		if bad.VerifyConstantTime() {
+			t.Errorf("%s: invalid proof verified", tt.name)
+		}
+	}
+}
+
+func TestVerifyConstantTimeBadParameters(t *testing.T) {
+	if new(Proof).VerifyConstantTime() {
+		t.Fatal("verified the zero proof")
+	}
+
+	G, H, M, Z, x := testStatement(t, elliptic.P256())
+	proof, err := NewProof(crypto.SHA256, G, H, M, Z, x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, perturb := range map[string]func(*Proof){
+		"unavailable hash":  func(pr *Proof) { pr.hash = crypto.MD4 },
+		"zero hash":         func(pr *Proof) { pr.hash = 0 },
+		"unavailable hash2": func(pr *Proof) { pr.hash2 = crypto.MD4 },
+		"unknown order":     func(pr *Proof) { pr.opts.TranscriptOrder = TranscriptOrder(99) },
+		"short challenge":   func(pr *Proof) { pr.opts.ChallengeBits = 1 },
+	} {
+		bad := *proof
+		perturb(&bad)
+		if bad.VerifyConstantTime() {
+			t.Errorf("%s: verified", name)
+		}
+	}
+}
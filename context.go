@@ -0,0 +1,92 @@
+package dleq
+
+import (
+	"crypto"
+	crand "crypto/rand"
+	"io"
+	"math/big"
+)
+
+// NewProofWithContext proves statement with a domain-separation context
+// folded into the transcript, so that a proof made for one protocol or
+// version can't be replayed in another. Like the Merkle root of
+// NewProofInSet, the context is not serialized; verifiers supply it to
+// VerifyContext. A nil ctx is absent, giving the same proof as NewProof,
+// while an empty one is bound.
+func NewProofWithContext(hash crypto.Hash, statement *Statement, x *big.Int, ctx []byte) (*Proof, error) {
+	proof := newProof(hash, statement, nil)
+	proof.context = contextField(ctx)
+	if _, _, err := proof.prove(x, crand.Reader); err != nil {
+		return nil, err
+	}
+	return proof, nil
+}
+
+// VerifyContext verifies a proof made for the given context, with nil
+// meaning no context as it does for NewProofWithContext.
+func (pr *Proof) VerifyContext(ctx []byte) bool {
+	bound := *pr
+	bound.context = contextField(ctx)
+	return bound.Verify()
+}
+
+// contextField copies ctx for binding, keeping a nil context absent.
+func contextField(ctx []byte) []byte {
+	if ctx == nil {
+		return nil
+	}
+	return append([]byte{}, ctx...)
+}
+
+// Reprove is a migration helper for the prover: it makes a fresh proof of
+// old's statement under the context newCtx, keeping everything else about
+// old, such as its hash and options, except that a salted proof gets a
+// fresh salt. Verifiers can't do this themselves; it takes the witness, and
+// fails with ErrWrongWitness if old isn't about this prover's witness. old
+// itself is not verified, since its context may have been lost in
+// serialization.
+func (p *Prover) Reprove(old *Proof, newCtx []byte) (*Proof, error) {
+	stmt := old.Statement()
+	if err := stmt.check(); err != nil {
+		return nil, err
+	}
+	if !old.hash.Available() || (old.hash2 != 0 && !old.hash2.Available()) {
+		return nil, ErrUnknownHash
+	}
+	if err := old.opts.checkChallengeBits(stmt.G.Curve); err != nil {
+		return nil, err
+	}
+	if err := old.opts.checkOrder(); err != nil {
+		return nil, err
+	}
+	if !stmt.holdsFor(p.x) {
+		return nil, ErrWrongWitness
+	}
+
+	proof := newProof(old.hash, stmt, nil)
+	proof.params = old.params
+	proof.context = contextField(newCtx)
+	if old.salt != nil {
+		proof.salt = make([]byte, SaltSize)
+		if _, err := io.ReadFull(crand.Reader, proof.salt); err != nil {
+			return nil, randomnessError(err)
+		}
+	}
+	if err := p.prove(proof); err != nil {
+		return nil, err
+	}
+	return proof, nil
+}
+
+// holdsFor reports whether H = xG and Z = xM.
+func (stmt *Statement) holdsFor(x *big.Int) bool {
+	h, err := PublicKey(stmt.G, x)
+	if err != nil {
+		return false
+	}
+	z, err := PublicKey(stmt.M, x)
+	if err != nil {
+		return false
+	}
+	return h.equal(stmt.H) && z.equal(stmt.Z)
+}
@@ -0,0 +1,106 @@
+package dleq
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/elliptic"
+	"testing"
+)
+
+func TestProofWithContext(t *testing.T) {
+	G, H, M, Z, x := testStatement(t, elliptic.P256())
+	stmt := &Statement{G: G, H: H, M: M, Z: Z}
+	proof, err := NewProofWithContext(crypto.SHA256, stmt, x, []byte("protocol v1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !proof.VerifyContext([]byte("protocol v1")) {
+		t.Fatal("proof was invalid in its context")
+	}
+	if proof.VerifyContext([]byte("protocol v2")) {
+		t.Fatal("proof verified in another context")
+	}
+
+	// The context isn't serialized, so a decoded proof needs it supplied.
+	data, err := proof.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded := new(Proof)
+	if err := decoded.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Verify() || !decoded.VerifyContext([]byte("protocol v1")) {
+		t.Fatal("decoded proof did not need its context")
+	}
+
+	// A nil context is absent, as for a plain proof; an empty one is not.
+	plain, err := NewProofWithContext(crypto.SHA256, stmt, x, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !plain.Verify() || !plain.VerifyContext(nil) || plain.VerifyContext([]byte{}) {
+		t.Fatal("nil context was bound")
+	}
+	empty, err := NewProofWithContext(crypto.SHA256, stmt, x, []byte{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if empty.VerifyContext(nil) || !empty.VerifyContext([]byte{}) {
+		t.Fatal("empty context was not bound")
+	}
+}
+
+func TestReprove(t *testing.T) {
+	G, H, M, Z, x := testStatement(t, elliptic.P256())
+	stmt := &Statement{G: G, H: H, M: M, Z: Z}
+	old, err := NewProofWithContext(crypto.SHA384, stmt, x, []byte("protocol v1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	prover := NewProver(crypto.SHA256, x)
+	migrated, err := prover.Reprove(old, []byte("protocol v2"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !migrated.VerifyContext([]byte("protocol v2")) {
+		t.Fatal("migrated proof was invalid in the new context")
+	}
+	if migrated.VerifyContext([]byte("protocol v1")) {
+		t.Fatal("migrated proof verified in the old context")
+	}
+	if migrated.hash != crypto.SHA384 {
+		t.Fatal("migration changed the proof's hash")
+	}
+
+	// A salted proof is migrated under a fresh salt.
+	salted, err := NewProofWithSalt(crypto.SHA256, stmt, x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resalted, err := prover.Reprove(salted, []byte("protocol v2"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !resalted.VerifyContext([]byte("protocol v2")) {
+		t.Fatal("migrated salted proof was invalid")
+	}
+	if len(resalted.salt) != SaltSize || bytes.Equal(resalted.salt, salted.salt) {
+		t.Fatal("migration kept the old salt")
+	}
+
+	// Only the holder of the witness can migrate.
+	_, _, _, _, other := testStatement(t, elliptic.P256())
+	if _, err := NewProver(crypto.SHA256, other).Reprove(old, []byte("protocol v2")); err != ErrWrongWitness {
+		t.Fatalf("expected ErrWrongWitness, got %v", err)
+	}
+
+	// Nor can a proof with options that would leave points out of the
+	// transcript.
+	unordered := *old
+	unordered.opts.TranscriptOrder = TranscriptOrder(99)
+	if _, err := prover.Reprove(&unordered, []byte("protocol v2")); err != ErrTranscriptOrder {
+		t.Fatalf("unknown transcript order: expected ErrTranscriptOrder, got %v", err)
+	}
+}
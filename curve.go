@@ -2,6 +2,7 @@ package dleq
 
 import (
 	"crypto/elliptic"
+	"crypto/subtle"
 	"io"
 	"math/big"
 )
@@ -15,45 +16,359 @@ func (p *Point) IsOnCurve() bool {
 	return p.Curve.IsOnCurve(p.X, p.Y)
 }
 
+// Marshal returns the uncompressed SEC 1 encoding of the normalized point,
+// which is also how points enter the transcript.
 func (p *Point) Marshal() []byte {
+	p = p.Normalize()
 	return elliptic.Marshal(p.Curve, p.X, p.Y)
 }
 
+// Normalize returns the point with its coordinates reduced into [0, p), the
+// canonical affine form that encodings and the transcript are computed
+// from. The crypto/elliptic curves always return reduced coordinates, so
+// for them this is p itself, but a custom Curve implementation may hand
+// back leftovers of its internal representation that compare and encode
+// differently for the same point.
+func (p *Point) Normalize() *Point {
+	P := p.Curve.Params().P
+	canonical := func(k *big.Int) bool { return k.Sign() >= 0 && k.Cmp(P) < 0 }
+	if canonical(p.X) && canonical(p.Y) {
+		return p
+	}
+	return &Point{Curve: p.Curve, X: new(big.Int).Mod(p.X, P), Y: new(big.Int).Mod(p.Y, P)}
+}
+
+// Unmarshal decodes a SEC 1 point on curve in whichever encoding its
+// leading byte announces: 0x04 uncompressed, 0x02 or 0x03 compressed, or
+// 0x06 or 0x07 hybrid. It rejects the identity, 0x00, with
+// ErrIdentityPoint and any other prefix with ErrUnknownEncoding. Use
+// UnmarshalEncoding to insist on one encoding.
 func (p *Point) Unmarshal(curve elliptic.Curve, data []byte) error {
-	p.Curve = curve
-	p.X, p.Y = elliptic.Unmarshal(curve, data)
-	if p.X == nil {
+	if len(data) == 0 {
 		return ErrInvalidPoint
 	}
+	if data[0] == 0x00 {
+		return ErrIdentityPoint
+	}
+	enc := prefixEncoding(data[0])
+	if enc == 0 {
+		return ErrUnknownEncoding
+	}
+	return p.UnmarshalEncoding(curve, enc, data)
+}
+
+// PublicKey computes g^x for a witness x in [1, q), the public key that goes
+// with g in a statement.
+func PublicKey(g *Point, x *big.Int) (*Point, error) {
+	if g == nil || g.Curve == nil || !g.IsOnCurve() {
+		return nil, ErrPointOffCurve
+	}
+	if x == nil || x.Sign() == 0 || !scalarInRange(g.Curve, x) {
+		return nil, ErrScalarRange
+	}
+	hx, hy := g.Curve.ScalarMult(g.X, g.Y, x.Bytes())
+	return &Point{Curve: g.Curve, X: hx, Y: hy}, nil
+}
+
+// A PointEncoding selects one of the SEC 1 point encodings. Its value is the
+// tag written ahead of each point in the binary proof format.
+type PointEncoding byte
+
+const (
+	Uncompressed PointEncoding = 1 // 0x04 || X || Y
+	Compressed   PointEncoding = 2 // (0x02 or 0x03) || X
+	Hybrid       PointEncoding = 3 // (0x06 or 0x07) || X || Y
+)
+
+// encodedLen is the length of a point on curve in the encoding enc, or 0 if
+// enc is unknown. The identity is the exception, encoded as the single byte
+// 0x00 whatever the encoding; decoders go by prefixLen instead.
+func (enc PointEncoding) encodedLen(curve elliptic.Curve) int {
+	byteLen := (curve.Params().BitSize + 7) / 8
+	switch enc {
+	case Uncompressed, Hybrid:
+		return 1 + 2*byteLen
+	case Compressed:
+		return 1 + byteLen
+	}
+	return 0
+}
+
+// MarshalEncoding encodes the point in SEC 1 form. The identity, which
+// crypto/elliptic represents as (0, 0), is the single byte 0x00 in every
+// encoding.
+func (p *Point) MarshalEncoding(enc PointEncoding) ([]byte, error) {
+	p = p.Normalize()
+	if enc.encodedLen(p.Curve) == 0 {
+		return nil, ErrUnknownEncoding
+	}
+	if p.IsIdentity() {
+		return []byte{0x00}, nil
+	}
+	switch enc {
+	case Uncompressed:
+		return elliptic.Marshal(p.Curve, p.X, p.Y), nil
+	case Compressed:
+		return elliptic.MarshalCompressed(p.Curve, p.X, p.Y), nil
+	case Hybrid:
+		// The hybrid form is the uncompressed form with the parity of Y
+		// folded into the prefix.
+		data := elliptic.Marshal(p.Curve, p.X, p.Y)
+		data[0] = 0x06 | byte(p.Y.Bit(0))
+		return data, nil
+	}
+	return nil, ErrUnknownEncoding
+}
+
+func (p *Point) UnmarshalEncoding(curve elliptic.Curve, enc PointEncoding, data []byte) error {
+	if enc.encodedLen(curve) == 0 {
+		return ErrUnknownEncoding
+	}
+	if len(data) == 1 && data[0] == 0x00 {
+		p.Curve, p.X, p.Y = curve, new(big.Int), new(big.Int)
+		return nil
+	}
+	var x, y *big.Int
+	switch enc {
+	case Uncompressed:
+		x, y = elliptic.Unmarshal(curve, data)
+	case Compressed:
+		x, y = elliptic.UnmarshalCompressed(curve, data)
+	case Hybrid:
+		if len(data) == 0 || data[0]&^1 != 0x06 {
+			return ErrInvalidPoint
+		}
+		parity := uint(data[0] & 1)
+		uncompressed := append([]byte{0x04}, data[1:]...)
+		x, y = elliptic.Unmarshal(curve, uncompressed)
+		if x != nil && y.Bit(0) != parity {
+			return ErrInvalidPoint
+		}
+	default:
+		return ErrUnknownEncoding
+	}
+	if x == nil {
+		return ErrInvalidPoint
+	}
+	p.Curve, p.X, p.Y = curve, x, y
 	return nil
 }
 
+// basePoint returns a copy of the standard generator of curve.
+func basePoint(curve elliptic.Curve) *Point {
+	params := curve.Params()
+	return &Point{Curve: curve, X: new(big.Int).Set(params.Gx), Y: new(big.Int).Set(params.Gy)}
+}
+
+// prefixEncoding is the encoding a SEC 1 point with the given leading byte
+// is in, or 0 if the byte doesn't begin a point.
+func prefixEncoding(prefix byte) PointEncoding {
+	switch prefix {
+	case 0x04:
+		return Uncompressed
+	case 0x02, 0x03:
+		return Compressed
+	case 0x06, 0x07:
+		return Hybrid
+	}
+	return 0
+}
+
+// prefixLen is the length of a point on curve in the encoding enc that
+// starts with the given byte: one byte for the identity, and otherwise
+// encodedLen. Only the encoded point itself says which it is.
+func (enc PointEncoding) prefixLen(curve elliptic.Curve, prefix byte) int {
+	if prefix == 0x00 && enc.encodedLen(curve) != 0 {
+		return 1
+	}
+	return enc.encodedLen(curve)
+}
+
+// encodedLen is the length of MarshalEncoding(enc), without encoding it.
+func (p *Point) encodedLen(enc PointEncoding) int {
+	if p.IsIdentity() && enc.encodedLen(p.Curve) != 0 {
+		return 1
+	}
+	return enc.encodedLen(p.Curve)
+}
+
+// ReadFrom reads one point on curve from r, in whichever SEC 1 encoding its
+// leading byte announces, consuming exactly that point's bytes; a 0x00 is
+// the identity on its own. It returns the number of bytes read. A stream
+// ending before the first byte gives io.EOF and one ending inside the point
+// io.ErrUnexpectedEOF; a leading byte naming no encoding gives
+// ErrUnknownEncoding, as Unmarshal reports it.
+//
+// Unlike io.ReaderFrom it takes the curve, since the encoding doesn't name
+// one, and it stops after one point rather than reading r to the end.
+func (p *Point) ReadFrom(curve elliptic.Curve, r io.Reader) (int64, error) {
+	var prefix [1]byte
+	if _, err := io.ReadFull(r, prefix[:]); err != nil {
+		return 0, err
+	}
+	if prefix[0] == 0x00 {
+		return 1, p.UnmarshalEncoding(curve, Uncompressed, prefix[:])
+	}
+	enc := prefixEncoding(prefix[0])
+	if enc == 0 {
+		return 1, ErrUnknownEncoding
+	}
+	data := make([]byte, enc.encodedLen(curve))
+	data[0] = prefix[0]
+	n, err := io.ReadFull(r, data[1:])
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		return int64(1 + n), err
+	}
+	return int64(len(data)), p.UnmarshalEncoding(curve, enc, data)
+}
+
+// WriteTo writes the point to w uncompressed, as Marshal encodes it. It
+// implements io.WriterTo.
+func (p *Point) WriteTo(w io.Writer) (int64, error) {
+	n, err := w.Write(p.Marshal())
+	return int64(n), err
+}
+
+// SameCurve reports whether a and b describe the same curve, regardless of
+// whether they are the same instance. Curves are compared by name and then by
+// their defining parameters; curves without a name are compared by
+// parameters alone.
+func SameCurve(a, b elliptic.Curve) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	if a == b {
+		return true
+	}
+	pa, pb := a.Params(), b.Params()
+	if pa.Name != "" && pb.Name != "" && pa.Name != pb.Name {
+		return false
+	}
+	return pa.BitSize == pb.BitSize &&
+		pa.P.Cmp(pb.P) == 0 &&
+		pa.N.Cmp(pb.N) == 0 &&
+		pa.B.Cmp(pb.B) == 0 &&
+		pa.Gx.Cmp(pb.Gx) == 0 &&
+		pa.Gy.Cmp(pb.Gy) == 0
+}
+
+// curves is the registry of curves that can be named in a serialized proof.
+// A curve's identifier is its index; zero is reserved.
+var curves = []elliptic.Curve{
+	nil,
+	elliptic.P224(),
+	elliptic.P256(),
+	elliptic.P384(),
+	elliptic.P521(),
+}
+
+func curveID(curve elliptic.Curve) (byte, bool) {
+	for id := 1; id < len(curves); id++ {
+		if SameCurve(curves[id], curve) {
+			return byte(id), true
+		}
+	}
+	return 0, false
+}
+
+// scalarLens caches ScalarLen for the registered curves, by identifier.
+var scalarLens = func() []int {
+	lens := make([]int, len(curves))
+	for id := 1; id < len(curves); id++ {
+		lens[id] = (curves[id].Params().N.BitLen() + 7) / 8
+	}
+	return lens
+}()
+
+// ScalarLen is the byte length of a scalar on curve, that of its group
+// order, which is what every fixed-length scalar encoding pads to. It is
+// cached for the registered curves and computed for any other.
+func ScalarLen(curve elliptic.Curve) int {
+	// Only the registry's own instances are looked up, since comparing
+	// them is cheaper than comparing parameters would be.
+	for id := 1; id < len(curves); id++ {
+		if curves[id] == curve {
+			return scalarLens[id]
+		}
+	}
+	return (curve.Params().N.BitLen() + 7) / 8
+}
+
+func curveByID(id byte) (elliptic.Curve, bool) {
+	if id == 0 || int(id) >= len(curves) {
+		return nil, false
+	}
+	return curves[id], true
+}
+
+// equal compares two points on the same curve in constant time.
+func (p *Point) equal(q *Point) bool {
+	return subtle.ConstantTimeCompare(p.Marshal(), q.Marshal()) == 1
+}
+
 // This is just a bitmask with the number of ones starting at 8 then
 // incrementing by index. To account for fields with bitsizes that are not a whole
 // number of bytes, we mask off the unnecessary bits. h/t agl
 var mask = []byte{0xff, 0x1, 0x3, 0x7, 0xf, 0x1f, 0x3f, 0x7f}
 
 func randScalar(curve elliptic.Curve, rand io.Reader) ([]byte, *big.Int, error) {
-	N := curve.Params().N // base point subgroup order
+	k := new(big.Int)
+	buf, err := sampleScalar(curve.Params().N, rand, make([]byte, ScalarLen(curve)), k)
+	if err != nil {
+		return nil, nil, err
+	}
+	return buf, k, nil
+}
+
+// sampleScalar is randScalar for a group of order N, setting k and reusing
+// buf if it is already the right length, so that a caller holding both
+// allocates nothing.
+func sampleScalar(N *big.Int, rand io.Reader, buf []byte, k *big.Int) ([]byte, error) {
 	bitSize := N.BitLen()
 	byteSize := (bitSize + 7) / 8
-	buf := make([]byte, byteSize)
+	if len(buf) != byteSize {
+		buf = make([]byte, byteSize)
+	}
 
 	// When in doubt, do what agl does in elliptic.go. Presumably
 	// new(big.Int).SetBytes(b).Mod(N) would introduce bias, so we're sampling.
 	for true {
 		_, err := io.ReadFull(rand, buf)
 		if err != nil {
-			return nil, nil, err
+			return nil, randomnessError(err)
 		}
 		// Mask to account for field sizes that are not a whole number of bytes.
 		buf[0] &= mask[bitSize%8]
 		// Check if scalar is in the correct range.
-		if new(big.Int).SetBytes(buf).Cmp(N) >= 0 {
+		if k.SetBytes(buf).Cmp(N) >= 0 {
 			continue
 		}
 		break
 	}
 
-	return buf, new(big.Int).SetBytes(buf), nil
+	return buf, nil
+}
+
+// scalarBytes returns k as a big-endian integer padded to the byte length of
+// the group order. k must already be reduced.
+func scalarBytes(curve elliptic.Curve, k *big.Int) []byte {
+	return k.FillBytes(make([]byte, ScalarLen(curve)))
+}
+
+// scalarInRange reports whether 0 <= k < q.
+func scalarInRange(curve elliptic.Curve, k *big.Int) bool {
+	return k.Sign() >= 0 && k.Cmp(curve.Params().N) < 0
+}
+
+// scalarEqual compares two scalars in constant time using their fixed-length
+// encodings. Values outside [0, q) never compare equal, so a non-canonical
+// encoding of a scalar can't stand in for the reduced one.
+func scalarEqual(curve elliptic.Curve, a, b *big.Int) bool {
+	if !scalarInRange(curve, a) || !scalarInRange(curve, b) {
+		return false
+	}
+	return subtle.ConstantTimeCompare(scalarBytes(curve, a), scalarBytes(curve, b)) == 1
 }
@@ -0,0 +1,257 @@
+package dleq
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/elliptic"
+	"io"
+	"math/big"
+	"testing"
+)
+
+// copyCurve returns a fresh instance of the generic implementation with the
+// same parameters as curve.
+func copyCurve(curve elliptic.Curve) *elliptic.CurveParams {
+	params := *curve.Params()
+	return &params
+}
+
+func TestSameCurve(t *testing.T) {
+	a, b := copyCurve(elliptic.P256()), copyCurve(elliptic.P256())
+	if !SameCurve(a, b) {
+		t.Error("identical parameters compared unequal")
+	}
+	if !SameCurve(a, elliptic.P256()) {
+		t.Error("copy compared unequal to the original")
+	}
+
+	a.Name, b.Name = "", ""
+	if !SameCurve(a, b) {
+		t.Error("unnamed curves with identical parameters compared unequal")
+	}
+
+	b.B = new(big.Int).Add(b.B, big.NewInt(1))
+	if SameCurve(a, b) {
+		t.Error("curves with different parameters compared equal")
+	}
+
+	if SameCurve(elliptic.P256(), elliptic.P384()) {
+		t.Error("P-256 compared equal to P-384")
+	}
+}
+
+func TestProofAcrossCurveInstances(t *testing.T) {
+	G, H, M, Z, x := testStatement(t, elliptic.P256())
+
+	// Same curve, two separately constructed instances.
+	G.Curve, M.Curve = copyCurve(elliptic.P256()), copyCurve(elliptic.P256())
+
+	proof, err := NewProof(crypto.SHA256, G, H, M, Z, x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !proof.Verify() {
+		t.Fatal("proof was invalid")
+	}
+}
+
+func TestPublicKey(t *testing.T) {
+	curve := elliptic.P256()
+	G, _, M, _, x := testStatement(t, curve)
+
+	H, err := PublicKey(G, x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	Z, err := PublicKey(M, x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	proof, err := NewProof(crypto.SHA256, G, H, M, Z, x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !proof.Verify() {
+		t.Fatal("proof over derived keys was invalid")
+	}
+
+	N := curve.Params().N
+	for _, bad := range []*big.Int{nil, big.NewInt(0), big.NewInt(-1), N, new(big.Int).Add(N, big.NewInt(1))} {
+		if _, err := PublicKey(G, bad); err != ErrScalarRange {
+			t.Errorf("x = %v: expected ErrScalarRange, got %v", bad, err)
+		}
+	}
+	offCurve := &Point{Curve: curve, X: G.X, Y: new(big.Int).Add(G.Y, big.NewInt(1))}
+	if _, err := PublicKey(offCurve, x); err != ErrPointOffCurve {
+		t.Errorf("expected ErrPointOffCurve, got %v", err)
+	}
+}
+
+func TestPointReadFrom(t *testing.T) {
+	curve := elliptic.P256()
+	G, H, M, _, _ := testStatement(t, curve)
+
+	// Four points back to back in different encodings, one of them the
+	// one-byte identity, then trailing data.
+	identity := &Point{Curve: curve, X: new(big.Int), Y: new(big.Int)}
+	var stream bytes.Buffer
+	if _, err := G.WriteTo(&stream); err != nil {
+		t.Fatal(err)
+	}
+	compressed, _ := H.MarshalEncoding(Compressed)
+	zero, _ := identity.MarshalEncoding(Compressed)
+	hybrid, _ := M.MarshalEncoding(Hybrid)
+	stream.Write(compressed)
+	stream.Write(zero)
+	stream.Write(hybrid)
+	stream.WriteString("rest")
+
+	for _, want := range []*Point{G, H, identity, M} {
+		var p Point
+		before := stream.Len()
+		n, err := p.ReadFrom(curve, &stream)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n != int64(before-stream.Len()) {
+			t.Fatalf("reported %d bytes, consumed %d", n, before-stream.Len())
+		}
+		if !p.equal(want) {
+			t.Fatal("read a different point")
+		}
+	}
+	if stream.String() != "rest" {
+		t.Fatalf("left %q in the stream", stream.String())
+	}
+}
+
+func TestPointReadFromShort(t *testing.T) {
+	curve := elliptic.P256()
+	G, _, _, _, _ := testStatement(t, curve)
+	data := G.Marshal()
+
+	var p Point
+	if n, err := p.ReadFrom(curve, bytes.NewReader(nil)); n != 0 || err != io.EOF {
+		t.Fatalf("empty stream: got (%d, %v)", n, err)
+	}
+	n, err := p.ReadFrom(curve, bytes.NewReader(data[:40]))
+	if n != 40 || err != io.ErrUnexpectedEOF {
+		t.Fatalf("truncated point: got (%d, %v)", n, err)
+	}
+	if n, err := p.ReadFrom(curve, bytes.NewReader([]byte{0x05, 1, 2})); n != 1 || err != ErrUnknownEncoding {
+		t.Fatalf("bad prefix: got (%d, %v)", n, err)
+	}
+	// The stream and the slice decoder agree on what a bad prefix is.
+	if err := p.Unmarshal(curve, []byte{0x05, 1, 2}); err != ErrUnknownEncoding {
+		t.Fatalf("Unmarshal of a bad prefix: got %v", err)
+	}
+}
+
+func TestScalarLen(t *testing.T) {
+	for _, tc := range []struct {
+		curve elliptic.Curve
+		want  int
+	}{
+		{elliptic.P224(), 28},
+		{elliptic.P256(), 32},
+		{elliptic.P384(), 48},
+		{elliptic.P521(), 66},
+	} {
+		name := tc.curve.Params().Name
+		if got := ScalarLen(tc.curve); got != tc.want {
+			t.Errorf("%s: ScalarLen is %d, expected %d", name, got, tc.want)
+		}
+		// A curve outside the registry gets the same answer, computed.
+		if got := ScalarLen(copyCurve(tc.curve)); got != tc.want {
+			t.Errorf("%s copy: ScalarLen is %d, expected %d", name, got, tc.want)
+		}
+		if len(scalarBytes(tc.curve, big.NewInt(1))) != tc.want {
+			t.Errorf("%s: scalarBytes is not ScalarLen long", name)
+		}
+	}
+}
+
+// sloppyCurve is P-256 handing back coordinates offset by the field prime,
+// as a backend leaking its internal representation might. It accepts such
+// coordinates as input too.
+type sloppyCurve struct{ elliptic.Curve }
+
+func (c sloppyCurve) reduce(x, y *big.Int) (*big.Int, *big.Int) {
+	P := c.Params().P
+	return new(big.Int).Mod(x, P), new(big.Int).Mod(y, P)
+}
+
+func (c sloppyCurve) offset(x, y *big.Int) (*big.Int, *big.Int) {
+	P := c.Params().P
+	return new(big.Int).Add(x, P), new(big.Int).Add(y, P)
+}
+
+func (c sloppyCurve) IsOnCurve(x, y *big.Int) bool {
+	return c.Curve.IsOnCurve(c.reduce(x, y))
+}
+
+func (c sloppyCurve) Add(x1, y1, x2, y2 *big.Int) (*big.Int, *big.Int) {
+	x1, y1 = c.reduce(x1, y1)
+	x2, y2 = c.reduce(x2, y2)
+	return c.offset(c.Curve.Add(x1, y1, x2, y2))
+}
+
+func (c sloppyCurve) Double(x, y *big.Int) (*big.Int, *big.Int) {
+	return c.offset(c.Curve.Double(c.reduce(x, y)))
+}
+
+func (c sloppyCurve) ScalarMult(x, y *big.Int, k []byte) (*big.Int, *big.Int) {
+	x, y = c.reduce(x, y)
+	return c.offset(c.Curve.ScalarMult(x, y, k))
+}
+
+func (c sloppyCurve) ScalarBaseMult(k []byte) (*big.Int, *big.Int) {
+	return c.offset(c.Curve.ScalarBaseMult(k))
+}
+
+func TestNormalize(t *testing.T) {
+	curve := sloppyCurve{elliptic.P256()}
+	G, _, M, _, x := testStatement(t, elliptic.P256())
+	G, M = &Point{Curve: curve, X: G.X, Y: G.Y}, &Point{Curve: curve, X: M.X, Y: M.Y}
+	H, err := PublicKey(G, x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	Z, err := PublicKey(M, x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if H.X.Cmp(curve.Params().P) < 0 {
+		t.Fatal("the sloppy curve returned reduced coordinates")
+	}
+
+	normal := H.Normalize()
+	if normal.X.Cmp(curve.Params().P) >= 0 || normal.Y.Cmp(curve.Params().P) >= 0 {
+		t.Fatal("Normalize left a coordinate unreduced")
+	}
+	if !bytes.Equal(H.Marshal(), normal.Marshal()) {
+		t.Fatal("a point and its normal form encode differently")
+	}
+	if G.Normalize() != G {
+		t.Fatal("Normalize copied a point that was already canonical")
+	}
+
+	// The sloppy commitments hash the same as the reduced ones, so the
+	// proof verifies on either curve.
+	proof, err := NewProof(crypto.SHA256, G, H, M, Z, x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !proof.Verify() {
+		t.Fatal("proof on the sloppy curve was invalid")
+	}
+	p256 := elliptic.P256()
+	onP256 := func(p *Point) *Point {
+		n := p.Normalize()
+		return &Point{Curve: p256, X: n.X, Y: n.Y}
+	}
+	reduced := &Proof{G: onP256(G), H: onP256(H), M: onP256(M), Z: onP256(Z), C: proof.C, R: proof.R, params: proof.params}
+	if !reduced.Verify() {
+		t.Fatal("proof did not verify with reduced coordinates")
+	}
+}
@@ -0,0 +1,69 @@
+package dleq
+
+import (
+	"crypto"
+	"crypto/subtle"
+	"math/big"
+)
+
+// A DetachedProof stores only a digest of its statement alongside (C, R),
+// for when the full statement is kept elsewhere. The verifier supplies the
+// statement, which must hash to the stored digest before the proof is
+// checked. It sits between a full Proof, which carries the statement, and
+// bare (C, R), where the verifier must already have the statement pinned.
+type DetachedProof struct {
+	StatementHash []byte   // H(G || H || M || Z)
+	R             *big.Int // response value
+	C             *big.Int // challenge
+
+	digest crypto.Hash
+	params
+}
+
+// digest hashes the statement's points in the order G, H, M, Z, whatever
+// the proof's TranscriptOrder.
+func (stmt *Statement) digest(hash crypto.Hash) []byte {
+	H := hash.New()
+	H.Write(stmt.G.Marshal())
+	H.Write(stmt.H.Marshal())
+	H.Write(stmt.M.Marshal())
+	H.Write(stmt.Z.Marshal())
+	return H.Sum(nil)
+}
+
+// Detach drops the statement from pr, keeping its digest under hash. It
+// returns nil if hash is unavailable or pr's statement is incomplete.
+func (pr *Proof) Detach(hash crypto.Hash) *DetachedProof {
+	if !hash.Available() || pr.Statement().check() != nil {
+		return nil
+	}
+	return &DetachedProof{
+		StatementHash: pr.Statement().digest(hash),
+		R:             pr.R,
+		C:             pr.C,
+		digest:        hash,
+		params:        pr.params,
+	}
+}
+
+// Verify checks that stmt is the statement the proof was detached from and
+// that the proof is valid for it. A DetachedProof that didn't come from
+// Detach has no digest hash and never verifies.
+func (dp *DetachedProof) Verify(stmt *Statement) bool {
+	if dp == nil || stmt == nil || stmt.check() != nil || dp.R == nil || dp.C == nil {
+		return false
+	}
+	if !dp.digest.Available() {
+		return false
+	}
+	if subtle.ConstantTimeCompare(stmt.digest(dp.digest), dp.StatementHash) != 1 {
+		return false
+	}
+	pr := &Proof{
+		G: stmt.G, M: stmt.M,
+		H: stmt.H, Z: stmt.Z,
+		R: dp.R, C: dp.C,
+		params: dp.params,
+	}
+	return pr.Verify()
+}
@@ -0,0 +1,72 @@
+package dleq
+
+import (
+	"crypto"
+	"crypto/elliptic"
+	"testing"
+)
+
+func TestDetachedProof(t *testing.T) {
+	G, H, M, Z, x := testStatement(t, elliptic.P256())
+	proof, err := NewProof(crypto.SHA256, G, H, M, Z, x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	detached := proof.Detach(crypto.SHA256)
+	if len(detached.StatementHash) != crypto.SHA256.Size() {
+		t.Fatal("statement hash has the wrong size")
+	}
+	if !detached.Verify(&Statement{G: G, H: H, M: M, Z: Z}) {
+		t.Fatal("detached proof was invalid")
+	}
+}
+
+func TestDetachedProofRejectsOtherStatement(t *testing.T) {
+	curve := elliptic.P256()
+	G, H, M, Z, x := testStatement(t, curve)
+	proof, err := NewProof(crypto.SHA256, G, H, M, Z, x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	detached := proof.Detach(crypto.SHA256)
+
+	// A different, equally valid statement about the same x.
+	G2, _, M2, _, _ := testStatement(t, curve)
+	H2x, H2y := curve.ScalarMult(G2.X, G2.Y, x.Bytes())
+	Z2x, Z2y := curve.ScalarMult(M2.X, M2.Y, x.Bytes())
+	other := &Statement{
+		G: G2, H: &Point{Curve: curve, X: H2x, Y: H2y},
+		M: M2, Z: &Point{Curve: curve, X: Z2x, Y: Z2y},
+	}
+	if detached.Verify(other) {
+		t.Fatal("detached proof verified against a mismatched statement")
+	}
+}
+
+func TestDetachedProofBadHash(t *testing.T) {
+	G, H, M, Z, x := testStatement(t, elliptic.P256())
+	proof, err := NewProof(crypto.SHA256, G, H, M, Z, x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, hash := range []crypto.Hash{0, crypto.MD4} {
+		if proof.Detach(hash) != nil {
+			t.Errorf("detached under unavailable hash %d", hash)
+		}
+	}
+	if new(Proof).Detach(crypto.SHA256) != nil {
+		t.Error("detached an incomplete proof")
+	}
+
+	// A literal has no digest hash to check the statement with.
+	detached := proof.Detach(crypto.SHA256)
+	literal := &DetachedProof{StatementHash: detached.StatementHash, R: proof.R, C: proof.C}
+	stmt := &Statement{G: G, H: H, M: M, Z: Z}
+	if literal.Verify(stmt) {
+		t.Error("verified a DetachedProof literal")
+	}
+	var nilProof *DetachedProof
+	if nilProof.Verify(stmt) {
+		t.Error("verified a nil DetachedProof")
+	}
+}
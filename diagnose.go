@@ -0,0 +1,63 @@
+//go:build dleqdebug
+
+package dleq
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// DiagnoseFailure explains why a statement (g, h, m, z) doesn't hold for the
+// claimed witness x, checking g^x == h and m^x == z independently. A proof
+// can fail to verify because the logs genuinely differ, or because one of
+// the public keys was computed with the wrong scalar or decoded on the wrong
+// curve; the report says which.
+//
+// This is strictly a debugging tool. It handles the secret x with none of the
+// care the prover does and prints facts about it, so it is only built with
+// the dleqdebug tag and must never be reached from production code.
+func DiagnoseFailure(g, h, m, z *Point, x *big.Int) string {
+	stmt := &Statement{G: g, H: h, M: m, Z: z}
+	switch err := stmt.check(); {
+	case err == nil:
+	case errors.Is(err, ErrIncompleteProof):
+		return "statement is missing points"
+	case errors.Is(err, ErrInconsistentCurves):
+		return "points are on different curves: " + curveNames(g, h, m, z)
+	default:
+		return err.Error()
+	}
+	if x == nil || !scalarInRange(g.Curve, x) {
+		return "witness is not in [0, q)"
+	}
+
+	var failures []string
+	if !diagnoseKey(g, h, x) {
+		failures = append(failures, "g^x != h")
+	}
+	if !diagnoseKey(m, z, x) {
+		failures = append(failures, "m^x != z")
+	}
+	switch len(failures) {
+	case 0:
+		return "g^x == h and m^x == z; the statement holds for x, so check the proof's encoding and parameters"
+	case 1:
+		return failures[0] + "; the other key matches, so it was likely computed with a different scalar"
+	}
+	return "g^x != h and m^x != z; x is not the witness for either key"
+}
+
+func diagnoseKey(g, h *Point, x *big.Int) bool {
+	hx, hy := g.Curve.ScalarMult(g.X, g.Y, scalarBytes(g.Curve, x))
+	return (&Point{Curve: g.Curve, X: hx, Y: hy}).equal(h)
+}
+
+func curveNames(points ...*Point) string {
+	names := make([]string, len(points))
+	for i, p := range points {
+		names[i] = fmt.Sprintf("%q", p.Curve.Params().Name)
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,37 @@
+//go:build dleqdebug
+
+package dleq
+
+import (
+	"crypto/elliptic"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+func TestDiagnoseFailure(t *testing.T) {
+	curve := elliptic.P256()
+	G, H, M, Z, x := testStatement(t, curve)
+	wrong := new(big.Int).Add(x, big.NewInt(1))
+	Wx, Wy := curve.ScalarMult(M.X, M.Y, wrong.Bytes())
+	W := &Point{Curve: curve, X: Wx, Y: Wy}
+	other, _, _, _, _ := testStatement(t, elliptic.P384())
+
+	for _, tc := range []struct {
+		name       string
+		h, z       *Point
+		x          *big.Int
+		wantPrefix string
+	}{
+		{"valid", H, Z, x, "g^x == h and m^x == z"},
+		{"wrong z", H, W, x, "m^x != z;"},
+		{"wrong h", W, Z, x, "g^x != h;"},
+		{"wrong witness", H, Z, wrong, "g^x != h and m^x != z"},
+		{"mixed curves", other, Z, x, "points are on different curves"},
+	} {
+		got := DiagnoseFailure(G, tc.h, M, tc.z, tc.x)
+		if !strings.HasPrefix(got, tc.wantPrefix) {
+			t.Errorf("%s: got %q", tc.name, got)
+		}
+	}
+}
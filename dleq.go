@@ -7,22 +7,28 @@
 // be sent to the verifier by including the intermediate proof values (called
 // a, b in the paper) in the Fiat-Shamir hash step and using hash comparison to
 // determine proof validity instead of group element equality.
+//
+// Proof works over crypto/elliptic curves. GroupProof runs the same protocol
+// over any Group, of which there are two: CurveGroup for those same curves
+// and Ristretto255 for protocols standardized on ristretto255.
 package dleq
 
 import (
 	"crypto"
 	"crypto/elliptic"
-	"crypto/hmac"
 	crand "crypto/rand"
-	"errors"
+	"crypto/subtle"
+	"encoding/binary"
+	"hash"
+	"io"
 	"math/big"
 )
 
-var (
-	ErrInconsistentCurves = errors.New("points are on different curves")
-	ErrInvalidPoint       = errors.New("marshaled point was invalid")
-	ErrPointOffCurve      = errors.New("one of the points is off the curve")
-)
+// A Statement is the public claim that log_G(H) == log_M(Z).
+type Statement struct {
+	G, M *Point // generators known by both parties
+	H, Z *Point // "public keys" we want to compare
+}
 
 type Proof struct {
 	G, M *Point   // generators known by both parties
@@ -30,7 +36,20 @@ type Proof struct {
 	R    *big.Int // response value
 	C    *big.Int // hash of intermediate proof values to streamline equality checks
 
-	hash crypto.Hash
+	params
+}
+
+// params are everything beyond the statement that goes into computing the
+// challenge. They're shared by each of the proof forms.
+type params struct {
+	hash    crypto.Hash
+	hash2   crypto.Hash // second transcript hash of a hybrid proof, if any
+	opts    Options
+	root    []byte  // Merkle root of a committed statement set, if any
+	beacon  *Beacon // public randomness the proof is anchored to, if any
+	context []byte  // domain-separation context, if any
+	salt    []byte  // random per-proof salt, if any
+	parent  []byte  // C and R of the proof this one is a child of, if any
 }
 
 func (p *Proof) IsComplete() bool {
@@ -38,7 +57,7 @@ func (p *Proof) IsComplete() bool {
 }
 
 func (p *Proof) IsSane() bool {
-	if p.G.Curve != p.H.Curve || p.H.Curve != p.M.Curve || p.M.Curve != p.Z.Curve {
+	if !SameCurve(p.G.Curve, p.H.Curve) || !SameCurve(p.H.Curve, p.M.Curve) || !SameCurve(p.M.Curve, p.Z.Curve) {
 		return false
 	}
 	if !p.G.IsOnCurve() || !p.H.IsOnCurve() || !p.M.IsOnCurve() || !p.Z.IsOnCurve() {
@@ -51,87 +70,420 @@ func (p *Proof) IsSane() bool {
 // compute a proof that log_g(h) == log_m(z). If (g, h, m, z) are already known
 // to the verifier, then (c, r) is sufficient to check the proof.
 func NewProof(hash crypto.Hash, g, h, m, z *Point, x *big.Int) (*Proof, error) {
-	if g.Curve != h.Curve || h.Curve != m.Curve || m.Curve != z.Curve {
-		return nil, ErrInconsistentCurves
+	return NewProofWithOptions(hash, g, h, m, z, x, nil)
+}
+
+// NewProofWithOptions is NewProof with non-default options. The verifier has
+// to use the same options; they are carried along in the serialized forms.
+func NewProofWithOptions(hash crypto.Hash, g, h, m, z *Point, x *big.Int, opts *Options) (*Proof, error) {
+	proof := newProof(hash, &Statement{G: g, H: h, M: m, Z: z}, opts)
+	if _, _, err := proof.prove(x, crand.Reader); err != nil {
+		return nil, err
+	}
+	return proof, nil
+}
+
+// NewProofWithReader is NewProof with its nonce read from rand rather than
+// crypto/rand. A failure of rand is returned wrapped in
+// ErrRandomnessUnavailable.
+func NewProofWithReader(hash crypto.Hash, g, h, m, z *Point, x *big.Int, rand io.Reader) (*Proof, error) {
+	proof := newProof(hash, &Statement{G: g, H: h, M: m, Z: z}, nil)
+	if _, _, err := proof.prove(x, rand); err != nil {
+		return nil, err
+	}
+	return proof, nil
+}
+
+// newProof starts a proof of stmt with everything but C and R filled in.
+// Variants that bind more into the transcript set it up before proving.
+func newProof(hash crypto.Hash, stmt *Statement, opts *Options) *Proof {
+	proof := &Proof{
+		G: stmt.G, M: stmt.M,
+		H: stmt.H, Z: stmt.Z,
+		params: params{hash: hash},
+	}
+	if opts != nil {
+		proof.opts = *opts
+	}
+	return proof
+}
+
+// prove checks the statement and computes C and R. It additionally returns
+// the commitments (a, b) so that callers wanting the commitment form don't
+// recompute them.
+func (pr *Proof) prove(x *big.Int, rand io.Reader) (*Point, *Point, error) {
+	stmt := pr.Statement()
+	if err := stmt.check(); err != nil {
+		return nil, nil, err
+	}
+	if !pr.hash.Available() || (pr.hash2 != 0 && !pr.hash2.Available()) {
+		return nil, nil, ErrUnknownHash
+	}
+	if pr.opts.RequireDistinct && !stmt.DistinctPoints() {
+		return nil, nil, ErrRepeatedPoint
+	}
+	if err := pr.opts.checkChallengeBits(pr.G.Curve); err != nil {
+		return nil, nil, err
+	}
+	if err := pr.opts.checkOrder(); err != nil {
+		return nil, nil, err
+	}
+	s, a, b, err := commit(pr.G.Curve, pr.G, pr.M, rand)
+	if err != nil {
+		return nil, nil, err
+	}
+	pr.finish(x, s, a, b, nil)
+	if err := pr.selfVerify(); err != nil {
+		return nil, nil, err
+	}
+	return a, b, nil
+}
+
+// selfVerify verifies a proof just built if its options ask for it, and
+// clears the response if it doesn't verify.
+func (pr *Proof) selfVerify() error {
+	if !pr.opts.SelfVerify || pr.VerifyError() == nil {
+		return nil
 	}
-	if !g.IsOnCurve() || !h.IsOnCurve() || !m.IsOnCurve() || !z.IsOnCurve() {
-		return nil, ErrPointOffCurve
+	pr.C, pr.R = nil, nil
+	return ErrSelfVerify
+}
+
+// finish computes C and R from the nonce s and the commitments (a, b),
+// keeping intermediates in ar if it isn't nil.
+func (pr *Proof) finish(x, s *big.Int, a, b *Point, ar *arena) {
+	pr.C = pr.challenge(a, b)
+	curve := pr.G.Curve
+	c := pr.opts.responseChallenge(curve, pr.C)
+	if ar == nil {
+		pr.R = ComputeResponse(s, c, x, curve.Params().N)
+		return
+	}
+	pr.R = ar.response(s, c, x, curve.Params().N)
+}
+
+// Statement returns the statement the proof is about.
+func (pr *Proof) Statement() *Statement {
+	return &Statement{G: pr.G, H: pr.H, M: pr.M, Z: pr.Z}
+}
+
+// commit samples the nonce s and computes the commitments (a, b) = (g^s, m^s).
+// This is the prover's first move in the interactive protocol.
+func commit(curve elliptic.Curve, g, m *Point, rand io.Reader) (*big.Int, *Point, *Point, error) {
+	s := new(big.Int)
+	_, a, b, err := commitInto(curve, g, m, rand, nil, s)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return s, a, b, nil
+}
+
+// commitInto is commit sampling the nonce into s and sBytes, which are
+// reused as in sampleScalar. It returns the nonce bytes it used.
+func commitInto(curve elliptic.Curve, g, m *Point, rand io.Reader, sBytes []byte, s *big.Int) ([]byte, *Point, *Point, error) {
+	if err := checkHealth(rand); err != nil {
+		return nil, nil, nil, err
 	}
-	curve := g.Curve
 
 	// s is a random element of Z/qZ
-	sBytes, s, err := randScalar(curve, crand.Reader)
+	sBytes, err := sampleScalar(curve.Params().N, rand, sBytes, s)
 	if err != nil {
-		return nil, err
+		return nil, nil, nil, err
 	}
 
 	// (a, b) = (g^s, m^s)
 	Ax, Ay := curve.ScalarMult(g.X, g.Y, sBytes)
 	Bx, By := curve.ScalarMult(m.X, m.Y, sBytes)
+	a := &Point{Curve: curve, X: Ax, Y: Ay}
+	b := &Point{Curve: curve, X: Bx, Y: By}
+	return sBytes, a, b, nil
+}
+
+// ComputeResponse computes the prover's final move r = s - cx (mod N) for
+// nonce s, challenge c and witness x, exactly as NewProof does. It's exported
+// for building other flows, such as interactive or composed proofs, on the
+// same convention: the verifier checks a = rG + cH.
+//
+// Expressing this as r = s - cx instead of r = s + cx saves us an
+// inversion of c when calculating A and B on the verification side.
+func ComputeResponse(s, c, x, N *big.Int) *big.Int {
+	r := new(big.Int).Neg(c) // r = -c
+	r.Mul(r, x)              // r = -cx
+	r.Add(r, s)              // r = s - cx
+	r.Mod(r, N)              // r = r (mod q)
+	return r
+}
 
-	// c = H(g, h, z, a, b)
-	// Note: in the paper this is H(m, z, a, b) to constitute a signature over
-	// m and prevent existential forgery. What we care about here isn't
-	// committing to a particular m but the equality with the specific public
-	// key h.
-	H := hash.New()
-	H.Write(g.Marshal())
-	H.Write(h.Marshal())
-	H.Write(m.Marshal())
-	H.Write(z.Marshal())
-	H.Write(elliptic.Marshal(curve, Ax, Ay))
-	H.Write(elliptic.Marshal(curve, Bx, By))
-	cBytes := H.Sum(nil)
-
-	// Expressing this as r = s - cx instead of r = s + cx saves us an
-	// inversion of c when calculating A and B on the verification side.
-	c := new(big.Int).SetBytes(cBytes)
-	c.Mod(c, curve.Params().N) // c = c (mod q)
-	r := new(big.Int).Neg(c)   // r = -c
-	r.Mul(r, x)                // r = -cx
-	r.Add(r, s)                // r = s - cx
-	r.Mod(r, curve.Params().N) // r = r (mod q)
+func (stmt *Statement) check() error {
+	points := [...]struct {
+		name string
+		p    *Point
+	}{{"G", stmt.G}, {"H", stmt.H}, {"M", stmt.M}, {"Z", stmt.Z}}
+	for _, f := range points {
+		if f.p == nil {
+			return &ValidationError{Field: f.name, Reason: ErrIncompleteProof}
+		}
+	}
+	for _, f := range points[1:] {
+		if !SameCurve(stmt.G.Curve, f.p.Curve) {
+			return &ValidationError{Field: f.name, Reason: ErrInconsistentCurves}
+		}
+	}
+	for _, f := range points {
+		if !f.p.IsOnCurve() {
+			return &ValidationError{Field: f.name, Reason: ErrPointOffCurve}
+		}
+	}
+	return nil
+}
 
-	proof := &Proof{
-		G: g, M: m,
-		H: h, Z: z,
-		R: r, C: c,
-		hash: hash,
+// DistinctPoints reports whether G, H, M and Z are pairwise different. A
+// repeat is legitimate in some protocols, such as H == G for x = 1, but more
+// often it means the same point was wired in twice. The points must be set.
+func (stmt *Statement) DistinctPoints() bool {
+	points := []*Point{stmt.G, stmt.H, stmt.M, stmt.Z}
+	for i := range points {
+		for j := i + 1; j < len(points); j++ {
+			if points[i].equal(points[j]) {
+				return false
+			}
+		}
 	}
-	return proof, nil
+	return true
 }
 
-func (pr *Proof) Verify() bool {
-	if !pr.IsComplete() || !pr.IsSane() {
-		return false
+// challenge computes the Fiat-Shamir challenge c = H(g, h, m, z, a, b) for
+// the proof's statement, with the points in the options' TranscriptOrder,
+// reduced mod q and then truncated if the options ask for it.
+//
+// Note: in the paper this is H(m, z, a, b) to constitute a signature over m
+// and prevent existential forgery. What we care about here isn't committing
+// to a particular m but the equality with the specific public key h.
+func (pr *Proof) challenge(a, b *Point) *big.Int {
+	// A hybrid proof feeds the same transcript to both hashes and uses
+	// H1(transcript) || H2(transcript).
+	H1 := pr.hash.New()
+	var H io.Writer = H1
+	var H2 hash.Hash
+	if pr.hash2 != 0 {
+		H2 = pr.hash2.New()
+		H = io.MultiWriter(H1, H2)
+	}
+
+	if pr.opts.BindParameters {
+		bind(H, "curve", []byte(pr.G.Curve.Params().Name))
+		bind(H, "hash", []byte{byte(pr.hash), byte(pr.hash2)})
+	}
+	for _, p := range pr.opts.TranscriptOrder.points(pr.G, pr.H, pr.M, pr.Z) {
+		H.Write(p.Marshal())
+	}
+	if pr.root != nil {
+		bind(H, "merkle-root", pr.root)
+	}
+	if pr.beacon != nil {
+		bind(H, "beacon", pr.beacon.bytes())
 	}
+	if pr.context != nil {
+		bind(H, "context", pr.context)
+	}
+	if pr.salt != nil {
+		bind(H, "salt", pr.salt)
+	}
+	if pr.parent != nil {
+		bind(H, "parent", pr.parent)
+	}
+	H.Write(a.Marshal())
+	H.Write(b.Marshal())
+
+	digest := H1.Sum(nil)
+	if H2 != nil {
+		digest = H2.Sum(digest)
+	}
+	c := pr.opts.Endianness.decode(digest)
+	c.Mod(c, pr.G.Curve.Params().N)
+	return pr.opts.truncate(c)
+}
+
+// checkScalars checks that R is reduced and that C is reduced, nonzero and
+// no longer than the options allow, naming the one that isn't. An honest
+// challenge is zero with probability 1/q, or 2^-ChallengeBits if truncated,
+// while a zero challenge takes H and Z out of the verification equations
+// altogether, so it is rejected outright rather than computed through.
+func (pr *Proof) checkScalars() error {
 	curve := pr.G.Curve
+	if pr.opts.checkChallengeBits(curve) != nil || !pr.opts.challengeFits(pr.C) ||
+		pr.C.Sign() == 0 || !scalarInRange(curve, pr.C) {
+		return &ValidationError{Field: "C", Reason: ErrScalarRange}
+	}
+	if !scalarInRange(curve, pr.R) {
+		return &ValidationError{Field: "R", Reason: ErrScalarRange}
+	}
+	return nil
+}
 
-	// Prover gave us c = H(h, z, a, b)
-	// Calculate rG and rM, then C' = H(h, z, rG + cH, rM + cZ).
-	// C == C' is equivalent to checking the equalities.
+func (pr *Proof) scalarsValid() bool {
+	return pr.checkScalars() == nil
+}
+
+// bind writes a labeled, length-prefixed value into a transcript. Values
+// that are absent should be left out entirely rather than bound empty, so
+// that proofs which don't use them keep the plain transcript.
+func bind(H io.Writer, label string, value []byte) {
+	var lengths [8]byte
+	binary.BigEndian.PutUint16(lengths[:2], uint16(len(label)))
+	H.Write(lengths[:2])
+	H.Write([]byte(label))
+	binary.BigEndian.PutUint64(lengths[:], uint64(len(value)))
+	H.Write(lengths[:])
+	H.Write(value)
+}
+
+// commitments recomputes (a, b) = (rG + cH, rM + cZ) from the response and
+// challenge. Under the additive convention this is (rG - cH, rM - cZ).
+//
+// Scalars go to the curve at the full length of the order, so a zero is an
+// explicit multiplication by zero rather than an empty byte string. Range
+// checks are the caller's; the reduction here only keeps the length fixed.
+func (pr *Proof) commitments() (*Point, *Point) {
+	curve := pr.G.Curve
+	c, r := pr.responseScalars()
 
 	// a = (g^r)(h^c)
 	// A = rG + cH
-	cHx, cHy := curve.ScalarMult(pr.H.X, pr.H.Y, pr.C.Bytes())
-	rGx, rGy := curve.ScalarMult(pr.G.X, pr.G.Y, pr.R.Bytes())
+	cHx, cHy := curve.ScalarMult(pr.H.X, pr.H.Y, c)
+	rGx, rGy := curve.ScalarMult(pr.G.X, pr.G.Y, r)
 	Ax, Ay := curve.Add(rGx, rGy, cHx, cHy)
 
 	// b = (m^r)(z^c)
 	// B = rM + cZ
-	cZx, cZy := curve.ScalarMult(pr.Z.X, pr.Z.Y, pr.C.Bytes())
-	rMx, rMy := curve.ScalarMult(pr.M.X, pr.M.Y, pr.R.Bytes())
+	cZx, cZy := curve.ScalarMult(pr.Z.X, pr.Z.Y, c)
+	rMx, rMy := curve.ScalarMult(pr.M.X, pr.M.Y, r)
 	Bx, By := curve.Add(rMx, rMy, cZx, cZy)
 
-	// C' = H(g, h, z, a, b) == C
-	H := pr.hash.New()
-	H.Write(pr.G.Marshal())
-	H.Write(pr.H.Marshal())
-	H.Write(pr.M.Marshal())
-	H.Write(pr.Z.Marshal())
-	H.Write(elliptic.Marshal(curve, Ax, Ay))
-	H.Write(elliptic.Marshal(curve, Bx, By))
-	c := H.Sum(nil)
-
-	return hmac.Equal(pr.C.Bytes(), c)
+	return &Point{Curve: curve, X: Ax, Y: Ay}, &Point{Curve: curve, X: Bx, Y: By}
+}
+
+// responseScalars returns the challenge as it enters the verification
+// equations and the response, both at the full length of the order.
+func (pr *Proof) responseScalars() (c, r []byte) {
+	curve := pr.G.Curve
+	N := curve.Params().N
+	fixed := func(k *big.Int) []byte {
+		return scalarBytes(curve, new(big.Int).Mod(k, N))
+	}
+	return fixed(pr.opts.responseChallenge(curve, pr.C)), fixed(pr.R)
+}
+
+func (pr *Proof) Verify() bool {
+	return pr.VerifyError() == nil
+}
+
+// VerifyError is Verify reporting why a proof was rejected: ErrInvalidProof
+// if it is well formed but doesn't verify, or the error naming what is
+// malformed about it.
+func (pr *Proof) VerifyError() error {
+	if err := pr.verifiable(); err != nil {
+		return err
+	}
+
+	// Prover gave us c = H(g, h, m, z, a, b)
+	// Calculate rG and rM, then C' = H(g, h, m, z, rG + cH, rM + cZ).
+	// C == C' is equivalent to checking the equalities.
+	a, b := pr.commitments()
+
+	// C' = H(g, h, m, z, a, b) == C
+	if !scalarEqual(pr.G.Curve, pr.C, pr.challenge(a, b)) {
+		return ErrInvalidProof
+	}
+	return nil
+}
+
+// verifiable makes the checks VerifyError does before it recomputes the
+// commitments, returning the error it reports for them.
+func (pr *Proof) verifiable() error {
+	if !pr.IsComplete() {
+		return ErrIncompleteProof
+	}
+	if err := pr.Statement().check(); err != nil {
+		return err
+	}
+	curve := pr.G.Curve
+	if !pr.hash.Available() || (pr.hash2 != 0 && !pr.hash2.Available()) {
+		return ErrUnknownHash
+	}
+	if err := pr.opts.checkChallengeBits(curve); err != nil {
+		return err
+	}
+	if err := pr.opts.checkOrder(); err != nil {
+		return err
+	}
+	if err := pr.checkScalars(); err != nil {
+		return err
+	}
+	if pr.opts.RequireDistinct && !pr.Statement().DistinctPoints() {
+		return ErrRepeatedPoint
+	}
+	return nil
+}
+
+// VerifyWithCommitments verifies pr given commitments (a, b) the caller has
+// already computed, such as the ones a Prover's Commit returned. The
+// commitments are not trusted: they must reproduce the challenge, which is
+// checked first since it's cheap, and satisfy a = rG + cH, b = rM + cZ.
+func VerifyWithCommitments(pr *Proof, a, b *Point) bool {
+	if pr.verifiable() != nil {
+		return false
+	}
+	curve := pr.G.Curve
+	if a == nil || b == nil || !SameCurve(a.Curve, curve) || !SameCurve(b.Curve, curve) {
+		return false
+	}
+	if !a.IsOnCurve() || !b.IsOnCurve() {
+		return false
+	}
+	if !scalarEqual(curve, pr.C, pr.challenge(a, b)) {
+		return false
+	}
+	A, B := pr.commitments()
+	return A.equal(a) && B.equal(b)
+}
+
+// VerifyWithCompressedCommitments is VerifyWithCommitments for a verifier
+// holding the commitments as SEC 1 compressed points on the proof's curve.
+// Bytes that don't decompress to a point are reported as a *FieldError
+// naming "a" or "b"; otherwise the error is nil and the bool is the verdict.
+func VerifyWithCompressedCommitments(pr *Proof, a, b []byte) (bool, error) {
+	if !pr.IsComplete() {
+		return false, ErrIncompleteProof
+	}
+	curve := pr.G.Curve
+	A, B := new(Point), new(Point)
+	if err := A.UnmarshalEncoding(curve, Compressed, a); err != nil {
+		return false, &FieldError{Field: "a", Err: err}
+	}
+	if err := B.UnmarshalEncoding(curve, Compressed, b); err != nil {
+		return false, &FieldError{Field: "b", Err: err}
+	}
+	return VerifyWithCommitments(pr, A, B), nil
+}
+
+// VerifyWithAllowedGenerators is Verify for systems that only trust a fixed
+// set of generator pairs, such as published protocol parameters. Anyone can
+// prove a statement over generators they made up, so the proof's (G, M) must
+// be one of the allowed pairs as well as verify. The list is scanned in full
+// with constant-time comparisons, so timing doesn't reveal which pair
+// matched.
+func (pr *Proof) VerifyWithAllowedGenerators(allowed [][2]*Point) bool {
+	if !pr.IsComplete() {
+		return false
+	}
+	found := 0
+	for _, pair := range allowed {
+		if pair[0] == nil || pair[1] == nil {
+			continue
+		}
+		found |= subtle.ConstantTimeCompare(pr.G.Marshal(), pair[0].Marshal()) &
+			subtle.ConstantTimeCompare(pr.M.Marshal(), pair[1].Marshal())
+	}
+	return found == 1 && pr.Verify()
 }
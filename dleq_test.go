@@ -5,6 +5,7 @@ import (
 	"crypto/elliptic"
 	"crypto/rand"
 	_ "crypto/sha256"
+	_ "crypto/sha512"
 	"math/big"
 	"testing"
 )
@@ -80,3 +81,389 @@ func TestInvalidProof(t *testing.T) {
 		t.Fatal("validated an invalid proof")
 	}
 }
+
+// testStatement builds random generators G, M and a witness x along with the
+// matching H = xG, Z = xM.
+func testStatement(t testing.TB, curve elliptic.Curve) (G, H, M, Z *Point, x *big.Int) {
+	xBytes, _, _, err := elliptic.GenerateKey(curve, rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, Gx, Gy, err := elliptic.GenerateKey(curve, rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, Mx, My, err := elliptic.GenerateKey(curve, rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	Hx, Hy := curve.ScalarMult(Gx, Gy, xBytes)
+	Zx, Zy := curve.ScalarMult(Mx, My, xBytes)
+
+	G = &Point{Curve: curve, X: Gx, Y: Gy}
+	H = &Point{Curve: curve, X: Hx, Y: Hy}
+	M = &Point{Curve: curve, X: Mx, Y: My}
+	Z = &Point{Curve: curve, X: Zx, Y: Zy}
+	return G, H, M, Z, new(big.Int).SetBytes(xBytes)
+}
+
+func TestProofChallengeWithWideHash(t *testing.T) {
+	// SHA-512 output is wider than the P-256 order, so the proof only
+	// verifies if both sides reduce the challenge the same way.
+	G, H, M, Z, x := testStatement(t, elliptic.P256())
+	proof, err := NewProof(crypto.SHA512, G, H, M, Z, x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !proof.Verify() {
+		t.Fatal("proof was invalid")
+	}
+}
+
+func TestComputeResponse(t *testing.T) {
+	curve := elliptic.P256()
+	N := curve.Params().N
+	G, H, _, _, x := testStatement(t, curve)
+
+	_, s, err := randScalar(curve, rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, c, err := randScalar(curve, rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := ComputeResponse(s, c, x, N)
+	if r.Sign() < 0 || r.Cmp(N) >= 0 {
+		t.Fatal("response was not reduced")
+	}
+
+	// a = sG must equal rG + cH
+	Ax, Ay := curve.ScalarMult(G.X, G.Y, s.Bytes())
+	rGx, rGy := curve.ScalarMult(G.X, G.Y, r.Bytes())
+	cHx, cHy := curve.ScalarMult(H.X, H.Y, c.Bytes())
+	Vx, Vy := curve.Add(rGx, rGy, cHx, cHy)
+	if Vx.Cmp(Ax) != 0 || Vy.Cmp(Ay) != 0 {
+		t.Fatal("rG + cH != a")
+	}
+}
+
+func TestVerifyAllocations(t *testing.T) {
+	// Verify on P-256 measured 84 allocations when this was written, nearly
+	// all of them big.Int results from the curve operations. The bound
+	// leaves a little room for toolchain variation; raise it deliberately.
+	const maxAllocs = 100
+	G, H, M, Z, x := testStatement(t, elliptic.P256())
+	proof, err := NewProof(crypto.SHA256, G, H, M, Z, x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	allocs := testing.AllocsPerRun(100, func() {
+		if !proof.Verify() {
+			t.Fatal("proof was invalid")
+		}
+	})
+	if allocs > maxAllocs {
+		t.Fatalf("Verify made %.0f allocations, expected at most %d", allocs, maxAllocs)
+	}
+}
+
+func TestVerifyRejectsDegenerateScalars(t *testing.T) {
+	curve := elliptic.P256()
+	N := curve.Params().N
+	G, H, M, Z, x := testStatement(t, curve)
+	proof, err := NewProof(crypto.SHA256, G, H, M, Z, x)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name string
+		C, R *big.Int
+	}{
+		{"zero challenge", new(big.Int), proof.R},
+		{"zero challenge and response", new(big.Int), new(big.Int)},
+		{"challenge equal to order", new(big.Int).Set(N), proof.R},
+		{"challenge plus order", new(big.Int).Add(proof.C, N), proof.R},
+		{"response equal to order", proof.C, new(big.Int).Set(N)},
+		{"response plus order", proof.C, new(big.Int).Add(proof.R, N)},
+		{"negative response", proof.C, new(big.Int).Sub(proof.R, N)},
+	} {
+		bad := *proof
+		bad.C, bad.R = tc.C, tc.R
+		if bad.Verify() {
+			t.Errorf("%s: proof verified", tc.name)
+		}
+		if bad.VerifyConstantTime() {
+			t.Errorf("%s: proof verified in constant time", tc.name)
+		}
+	}
+	if !proof.Verify() {
+		t.Fatal("original proof was invalid")
+	}
+}
+
+func TestDistinctPoints(t *testing.T) {
+	G, H, M, Z, x := testStatement(t, elliptic.P256())
+	strict := &Options{RequireDistinct: true}
+	if !(&Statement{G: G, H: H, M: M, Z: Z}).DistinctPoints() {
+		t.Fatal("random statement had repeated points")
+	}
+	if _, err := NewProofWithOptions(crypto.SHA256, G, H, M, Z, x, strict); err != nil {
+		t.Fatal(err)
+	}
+
+	names := []string{"G", "H", "M", "Z"}
+	for i := range names {
+		for j := i + 1; j < len(names); j++ {
+			points := []*Point{G, H, M, Z}
+			points[j] = points[i]
+			stmt := &Statement{G: points[0], H: points[1], M: points[2], Z: points[3]}
+			if stmt.DistinctPoints() {
+				t.Errorf("%s == %s: reported distinct", names[i], names[j])
+			}
+			_, err := NewProofWithOptions(crypto.SHA256, stmt.G, stmt.H, stmt.M, stmt.Z, x, strict)
+			if err != ErrRepeatedPoint {
+				t.Errorf("%s == %s: expected ErrRepeatedPoint, got %v", names[i], names[j], err)
+			}
+			// Without the option a repeat is allowed through.
+			if _, err := NewProof(crypto.SHA256, stmt.G, stmt.H, stmt.M, stmt.Z, x); err != nil {
+				t.Errorf("%s == %s: %v", names[i], names[j], err)
+			}
+		}
+	}
+}
+
+func TestRequireDistinctVerify(t *testing.T) {
+	// x = 1 is a true statement with H == G and Z == M.
+	G, _, M, _, _ := testStatement(t, elliptic.P256())
+	proof, err := NewProof(crypto.SHA256, G, G, M, M, big.NewInt(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !proof.Verify() {
+		t.Fatal("proof was invalid")
+	}
+	proof.opts.RequireDistinct = true
+	if proof.Verify() {
+		t.Fatal("strict verification accepted repeated points")
+	}
+}
+
+// brokenAddCurve is P-256 with an Add that returns its first argument.
+// Proving never adds points, so it makes proofs that look fine and don't
+// verify.
+type brokenAddCurve struct {
+	elliptic.Curve
+}
+
+func (c brokenAddCurve) Add(x1, y1, x2, y2 *big.Int) (*big.Int, *big.Int) {
+	return x1, y1
+}
+
+func TestSelfVerify(t *testing.T) {
+	G, H, M, Z, x := testStatement(t, elliptic.P256())
+	check := &Options{SelfVerify: true}
+	proof, err := NewProofWithOptions(crypto.SHA256, G, H, M, Z, x, check)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !proof.Verify() {
+		t.Fatal("self-verified proof was invalid")
+	}
+
+	curve := brokenAddCurve{elliptic.P256()}
+	G, M = &Point{Curve: curve, X: G.X, Y: G.Y}, &Point{Curve: curve, X: M.X, Y: M.Y}
+	H, Z = mustPublicKey(t, G, x), mustPublicKey(t, M, x)
+	if _, err := NewProof(crypto.SHA256, G, H, M, Z, x); err != nil {
+		t.Fatalf("without the option: %v", err)
+	}
+	proof, err = NewProofWithOptions(crypto.SHA256, G, H, M, Z, x, check)
+	if err != ErrSelfVerify {
+		t.Fatalf("expected ErrSelfVerify, got %v", err)
+	}
+	if proof != nil {
+		t.Fatal("returned a proof that didn't verify")
+	}
+	if IsValidationError(err) {
+		t.Error("a broken curve was reported as bad input")
+	}
+}
+
+func TestVerifyWithCommitments(t *testing.T) {
+	curve := elliptic.P256()
+	G, H, M, Z, x := testStatement(t, curve)
+	proof := newProof(crypto.SHA256, &Statement{G: G, H: H, M: M, Z: Z}, nil)
+	a, b, err := proof.prove(x, rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !VerifyWithCommitments(proof, a, b) {
+		t.Fatal("proof was invalid with its own commitments")
+	}
+	if VerifyWithCommitments(proof, b, a) {
+		t.Fatal("verified with swapped commitments")
+	}
+	if VerifyWithCommitments(proof, a, nil) {
+		t.Fatal("verified with a missing commitment")
+	}
+
+	unhashed := *proof
+	unhashed.hash = crypto.MD4
+	if VerifyWithCommitments(&unhashed, a, b) {
+		t.Fatal("verified with an unavailable hash")
+	}
+	unordered := *proof
+	unordered.opts.TranscriptOrder = TranscriptOrder(99)
+	if VerifyWithCommitments(&unordered, a, b) {
+		t.Fatal("verified with an unknown transcript order")
+	}
+
+	// Forge a proof whose challenge is computed over arbitrary commitments:
+	// it reproduces C but fails the group equations.
+	_, fake, err := randScalar(curve, rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	Ax, Ay := curve.ScalarBaseMult(fake.Bytes())
+	forgedA := &Point{Curve: curve, X: Ax, Y: Ay}
+	forged := *proof
+	forged.C = forged.challenge(forgedA, b)
+	if VerifyWithCommitments(&forged, forgedA, b) {
+		t.Fatal("verified with a forged commitment")
+	}
+}
+
+// benchmarkMatrix runs fn as a subtest for each registered curve and common
+// hash, with a fresh valid statement per curve.
+func benchmarkMatrix(b *testing.B, fn func(b *testing.B, hash crypto.Hash, G, H, M, Z *Point, x *big.Int)) {
+	for _, curve := range curves[1:] {
+		G, H, M, Z, x := testStatement(b, curve)
+		for _, hash := range []crypto.Hash{crypto.SHA256, crypto.SHA384, crypto.SHA512} {
+			b.Run(curve.Params().Name+"/"+hash.String(), func(b *testing.B) {
+				b.ReportAllocs()
+				fn(b, hash, G, H, M, Z, x)
+			})
+		}
+	}
+}
+
+func BenchmarkProve(b *testing.B) {
+	benchmarkMatrix(b, func(b *testing.B, hash crypto.Hash, G, H, M, Z *Point, x *big.Int) {
+		for i := 0; i < b.N; i++ {
+			if _, err := NewProof(hash, G, H, M, Z, x); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkVerify(b *testing.B) {
+	benchmarkMatrix(b, func(b *testing.B, hash crypto.Hash, G, H, M, Z *Point, x *big.Int) {
+		proof, err := NewProof(hash, G, H, M, Z, x)
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if !proof.Verify() {
+				b.Fatal("proof was invalid")
+			}
+		}
+	})
+}
+
+func TestVerifyWithAllowedGenerators(t *testing.T) {
+	curve := elliptic.P256()
+	G, H, M, Z, x := testStatement(t, curve)
+	otherG, _, otherM, _, _ := testStatement(t, curve)
+	proof, err := NewProof(crypto.SHA256, G, H, M, Z, x)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	published := [][2]*Point{{otherG, otherM}, {G, M}}
+	if !proof.VerifyWithAllowedGenerators(published) {
+		t.Fatal("proof over allowed generators was rejected")
+	}
+
+	// An attacker's own generators give a perfectly valid proof that the
+	// allowlist still has to reject.
+	attacker, err := NewProof(crypto.SHA256, otherG, H, M, Z, x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	forged, err := NewProof(crypto.SHA256, otherG, mustPublicKey(t, otherG, x), otherM, mustPublicKey(t, otherM, x), x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !forged.Verify() {
+		t.Fatal("proof over substituted generators was invalid")
+	}
+	for _, tc := range []struct {
+		name    string
+		pr      *Proof
+		allowed [][2]*Point
+	}{
+		{"disallowed pair", forged, [][2]*Point{{G, M}}},
+		{"mismatched pair", attacker, published},
+		{"swapped pair", proof, [][2]*Point{{M, G}}},
+		{"empty allowlist", proof, nil},
+		{"nil entry", proof, [][2]*Point{{nil, M}}},
+	} {
+		if tc.pr.VerifyWithAllowedGenerators(tc.allowed) {
+			t.Errorf("%s: proof was accepted", tc.name)
+		}
+	}
+}
+
+func mustPublicKey(t testing.TB, g *Point, x *big.Int) *Point {
+	h, err := PublicKey(g, x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return h
+}
+
+func TestVerifyWithCompressedCommitments(t *testing.T) {
+	G, H, M, Z, x := testStatement(t, elliptic.P256())
+	proof := newProof(crypto.SHA256, &Statement{G: G, H: H, M: M, Z: Z}, nil)
+	a, b, err := proof.prove(x, rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	aBytes, err := a.MarshalEncoding(Compressed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bBytes, err := b.MarshalEncoding(Compressed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ok, err := VerifyWithCompressedCommitments(proof, aBytes, bBytes)
+	if err != nil || !ok {
+		t.Fatalf("proof was invalid with its compressed commitments: %v", err)
+	}
+	if ok, err := VerifyWithCompressedCommitments(proof, bBytes, aBytes); err != nil || ok {
+		t.Fatalf("verified with swapped commitments: %v", err)
+	}
+
+	// Flipping the parity gives the negated point, which is on the curve
+	// but the wrong commitment.
+	negated := append([]byte{}, aBytes...)
+	negated[0] ^= 1
+	if ok, err := VerifyWithCompressedCommitments(proof, negated, bBytes); err != nil || ok {
+		t.Fatalf("verified with a negated commitment: %v", err)
+	}
+
+	uncompressed := a.Marshal()
+	_, err = VerifyWithCompressedCommitments(proof, uncompressed, bBytes)
+	if fe, ok := err.(*FieldError); !ok || fe.Field != "a" || fe.Err != ErrInvalidPoint {
+		t.Fatalf("expected a FieldError for a, got %v", err)
+	}
+	_, err = VerifyWithCompressedCommitments(proof, aBytes, bBytes[:10])
+	if fe, ok := err.(*FieldError); !ok || fe.Field != "b" {
+		t.Fatalf("expected a FieldError for b, got %v", err)
+	}
+}
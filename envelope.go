@@ -0,0 +1,108 @@
+package dleq
+
+import (
+	"encoding/binary"
+)
+
+// A ProofEnvelope carries a proof along with operational metadata that is
+// not part of the cryptographic statement. None of the metadata is
+// authenticated by the proof.
+type ProofEnvelope struct {
+	Proof     *Proof
+	Issuer    []byte // optional issuer identifier
+	CreatedAt int64  // optional creation time in Unix seconds; zero if unset
+}
+
+// The envelope is a version byte followed by a sequence of TLV records, each
+// a 1-byte tag, a uvarint length and the value. Readers skip records with
+// tags they don't know, so fields can be added without breaking old parsers.
+const (
+	envelopeVersion = 1
+
+	tagProof     = 1 // the binary proof format
+	tagIssuer    = 2
+	tagCreatedAt = 3 // 8-byte big-endian Unix seconds
+)
+
+func appendRecord(out []byte, tag byte, value []byte) []byte {
+	out = append(out, tag)
+	out = binary.AppendUvarint(out, uint64(len(value)))
+	return append(out, value...)
+}
+
+func (env *ProofEnvelope) MarshalBinary() ([]byte, error) {
+	if env.Proof == nil {
+		return nil, ErrMissingProof
+	}
+	// Don't write what a default decoder would refuse.
+	if len(env.Issuer) > DefaultMaxFieldSize {
+		return nil, ErrProofTooLarge
+	}
+	proof, err := env.Proof.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+
+	out := []byte{envelopeVersion}
+	out = appendRecord(out, tagProof, proof)
+	if env.Issuer != nil {
+		out = appendRecord(out, tagIssuer, env.Issuer)
+	}
+	if env.CreatedAt != 0 {
+		var ts [8]byte
+		binary.BigEndian.PutUint64(ts[:], uint64(env.CreatedAt))
+		out = appendRecord(out, tagCreatedAt, ts[:])
+	}
+	return out, nil
+}
+
+// UnmarshalBinary decodes an envelope within the default DecoderOptions.
+func (env *ProofEnvelope) UnmarshalBinary(data []byte) error {
+	return env.UnmarshalBinaryOptions(data, nil)
+}
+
+// UnmarshalBinaryOptions decodes an envelope, applying limits to the proof
+// it carries and MaxFieldSize to the issuer. A nil limits selects the
+// defaults.
+func (env *ProofEnvelope) UnmarshalBinaryOptions(data []byte, limits *DecoderOptions) error {
+	if len(data) < 1 || data[0] != envelopeVersion {
+		return ErrMalformedEnvelope
+	}
+	data = data[1:]
+
+	var decoded ProofEnvelope
+	for len(data) > 0 {
+		tag := data[0]
+		length, n := binary.Uvarint(data[1:])
+		if n <= 0 || length > uint64(len(data)-1-n) {
+			return ErrMalformedEnvelope
+		}
+		value := data[1+n : 1+n+int(length)]
+		data = data[1+n+int(length):]
+
+		switch tag {
+		case tagProof:
+			decoded.Proof = new(Proof)
+			if err := decoded.Proof.UnmarshalBinaryOptions(value, limits); err != nil {
+				return err
+			}
+		case tagIssuer:
+			if len(value) > limits.maxFieldSize() {
+				return ErrProofTooLarge
+			}
+			decoded.Issuer = append([]byte{}, value...)
+		case tagCreatedAt:
+			if len(value) != 8 {
+				return ErrMalformedEnvelope
+			}
+			decoded.CreatedAt = int64(binary.BigEndian.Uint64(value))
+		default:
+			// Unknown records are from a newer writer; skip them.
+		}
+	}
+	if decoded.Proof == nil {
+		return ErrMissingProof
+	}
+	*env = decoded
+	return nil
+}
@@ -0,0 +1,101 @@
+package dleq
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/elliptic"
+	"testing"
+)
+
+func testEnvelope(t *testing.T) *ProofEnvelope {
+	G, H, M, Z, x := testStatement(t, elliptic.P256())
+	proof, err := NewProof(crypto.SHA256, G, H, M, Z, x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &ProofEnvelope{Proof: proof, Issuer: []byte("issuer-1"), CreatedAt: 1500000000}
+}
+
+func TestEnvelopeRoundTrip(t *testing.T) {
+	env := testEnvelope(t)
+	data, err := env.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var decoded ProofEnvelope
+	if err := decoded.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(decoded.Issuer, env.Issuer) || decoded.CreatedAt != env.CreatedAt {
+		t.Fatal("metadata did not round trip")
+	}
+	if !decoded.Proof.Verify() {
+		t.Fatal("decoded proof was invalid")
+	}
+}
+
+func TestEnvelopeSkipsUnknownRecords(t *testing.T) {
+	env := testEnvelope(t)
+	data, err := env.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A newer writer adds records this version doesn't know about, both
+	// before and after the ones it does.
+	newer := []byte{envelopeVersion}
+	newer = appendRecord(newer, 0x7f, []byte("from the future"))
+	newer = append(newer, data[1:]...)
+	newer = appendRecord(newer, 0x80, make([]byte, 300))
+
+	var decoded ProofEnvelope
+	if err := decoded.UnmarshalBinary(newer); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(decoded.Issuer, env.Issuer) || decoded.CreatedAt != env.CreatedAt {
+		t.Fatal("metadata did not survive unknown records")
+	}
+	if !decoded.Proof.Verify() {
+		t.Fatal("decoded proof was invalid")
+	}
+}
+
+func TestEnvelopeRejectsTruncatedRecord(t *testing.T) {
+	data, err := testEnvelope(t).MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := new(ProofEnvelope).UnmarshalBinary(data[:len(data)-1]); err != ErrMalformedEnvelope {
+		t.Fatalf("expected ErrMalformedEnvelope, got %v", err)
+	}
+}
+
+func TestEnvelopeRequiresProof(t *testing.T) {
+	data := appendRecord([]byte{envelopeVersion}, tagIssuer, []byte("issuer-1"))
+	if err := new(ProofEnvelope).UnmarshalBinary(data); err != ErrMissingProof {
+		t.Fatalf("expected ErrMissingProof, got %v", err)
+	}
+}
+
+func TestEnvelopeIssuerLimit(t *testing.T) {
+	data, err := testEnvelope(t).MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	limits := &DecoderOptions{MaxFieldSize: len("issuer-1") - 1}
+	if err := new(ProofEnvelope).UnmarshalBinaryOptions(data, limits); err != ErrProofTooLarge {
+		t.Fatalf("expected ErrProofTooLarge, got %v", err)
+	}
+
+	// Nor is an envelope written that the default decoder would refuse.
+	env := testEnvelope(t)
+	env.Issuer = make([]byte, DefaultMaxFieldSize)
+	if _, err := env.MarshalBinary(); err != nil {
+		t.Fatalf("issuer at the limit: %v", err)
+	}
+	env.Issuer = make([]byte, DefaultMaxFieldSize+1)
+	if _, err := env.MarshalBinary(); err != ErrProofTooLarge {
+		t.Fatalf("issuer over the limit: expected ErrProofTooLarge, got %v", err)
+	}
+}
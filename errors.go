@@ -0,0 +1,143 @@
+package dleq
+
+import (
+	"errors"
+	"fmt"
+)
+
+// The package's sentinel errors. Every error it returns is one of these or
+// wraps one: a *ValidationError or *FieldError, or a failure of the
+// randomness source wrapped in ErrRandomnessUnavailable. Compare with
+// errors.Is, and use IsValidationError to tell bad input apart from
+// problems with the environment or the calling code.
+var (
+	// Statements and points.
+	ErrInconsistentCurves = errors.New("points are on different curves")
+	ErrInvalidPoint       = errors.New("marshaled point was invalid")
+	ErrPointOffCurve      = errors.New("one of the points is off the curve")
+	ErrIdentityPoint      = errors.New("point is the identity")
+	ErrIncompleteProof    = errors.New("proof is missing values")
+	ErrScalarRange        = errors.New("scalar is out of range")
+	ErrRepeatedPoint      = errors.New("statement uses the same point twice")
+	ErrUnequalPointCounts = errors.New("batch proof had unequal numbers of points")
+	ErrCountMismatch      = errors.New("numbers of statements and witnesses or responses differ")
+	ErrNotInGroup         = errors.New("element is not in the proof's group")
+	ErrUnsupportedKey     = errors.New("public key type or curve is not supported")
+	ErrInvalidProof       = errors.New("proof did not verify")
+	ErrSelfVerify         = errors.New("new proof did not verify; the curve implementation is broken")
+	ErrHashToPointBound   = errors.New("no point found within the counter bound")
+
+	// Options.
+	ErrChallengeBits   = errors.New("challenge bit length is out of range")
+	ErrTranscriptOrder = errors.New("unknown transcript order")
+
+	// Serialized proofs.
+	ErrUnknownEncoding      = errors.New("unknown point encoding")
+	ErrUnknownCurve         = errors.New("curve has no serialized identifier")
+	ErrUnknownHash          = errors.New("hash function is unknown or unavailable")
+	ErrMalformedProof       = errors.New("serialized proof was malformed")
+	ErrProofTooLarge        = errors.New("serialized proof exceeds the decoder limits")
+	ErrBufferTooSmall       = errors.New("buffer is too small for the serialized proof")
+	ErrMalformedEnvelope    = errors.New("serialized envelope was malformed")
+	ErrMissingProof         = errors.New("envelope has no proof")
+	ErrNonCanonicalEncoding = errors.New("ristretto255 encoding is not canonical")
+	ErrUnknownFormat        = errors.New("unknown foreign proof format")
+
+	// Fields of JSON proofs, reported inside a *FieldError.
+	ErrMissingField      = errors.New("required field is missing")
+	ErrFieldType         = errors.New("field has the wrong type")
+	ErrFieldHex          = errors.New("field is not valid hex")
+	ErrFieldLength       = errors.New("field has the wrong length for the curve")
+	ErrUnknownEndianness = errors.New("endianness must be \"big\" or \"little\"")
+	ErrUnknownConvention = errors.New("response convention must be \"subtractive\" or \"additive\"")
+
+	// Witness extraction.
+	ErrStatementMismatch  = errors.New("proofs are over different statements")
+	ErrCommitmentMismatch = errors.New("proofs have different commitments")
+	ErrSameChallenge      = errors.New("proofs have the same challenge")
+	ErrCommitmentReuse    = errors.New("commitment was reused with a different challenge")
+
+	// Randomness. The source's own error is wrapped along with it.
+	ErrRandomnessUnavailable = errors.New("randomness source failed")
+
+	// The interactive prover.
+	ErrStateConsumed    = errors.New("prover state was already finalized")
+	ErrChallengeInvalid = errors.New("challenge is out of range")
+	ErrNonceReuse       = errors.New("nonce was sampled twice; the randomness source is broken")
+	ErrWrongWitness     = errors.New("statement does not hold for the prover's witness")
+
+	// Threshold proofs.
+	ErrInvalidPartial = errors.New("partial proof does not match its share")
+	ErrShareMismatch  = errors.New("shares do not add up to the statement")
+)
+
+// validationErrors are the sentinels that mean the input was rejected. The
+// rest mean the environment failed, as ErrNonceReuse does, or the caller
+// misused the API, as with ErrStateConsumed.
+var validationErrors = []error{
+	ErrInconsistentCurves, ErrInvalidPoint, ErrPointOffCurve, ErrIdentityPoint,
+	ErrIncompleteProof, ErrScalarRange, ErrRepeatedPoint, ErrUnequalPointCounts,
+	ErrCountMismatch, ErrNotInGroup, ErrUnsupportedKey, ErrInvalidProof, ErrChallengeBits, ErrTranscriptOrder,
+	ErrUnknownEncoding, ErrUnknownCurve, ErrUnknownHash, ErrMalformedProof,
+	ErrProofTooLarge, ErrMalformedEnvelope, ErrMissingProof,
+	ErrNonCanonicalEncoding, ErrUnknownFormat,
+	ErrMissingField, ErrFieldType, ErrFieldHex, ErrFieldLength,
+	ErrUnknownEndianness, ErrUnknownConvention,
+	ErrStatementMismatch, ErrCommitmentMismatch, ErrSameChallenge, ErrCommitmentReuse,
+	ErrChallengeInvalid, ErrWrongWitness, ErrInvalidPartial, ErrShareMismatch,
+}
+
+// IsValidationError reports whether err means some input was rejected: a
+// malformed or inconsistent proof, statement, key or option. Such errors are
+// recoverable by fixing or discarding the input. It reports false for
+// failures of the environment, such as a broken randomness source, and for
+// misuse of the API.
+func IsValidationError(err error) bool {
+	var fieldErr *FieldError
+	if errors.As(err, &fieldErr) {
+		return true
+	}
+	for _, target := range validationErrors {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// randomnessError wraps an error from a randomness source, keeping both it
+// and ErrRandomnessUnavailable visible to errors.Is.
+func randomnessError(err error) error {
+	return fmt.Errorf("dleq: %w: %w", ErrRandomnessUnavailable, err)
+}
+
+// A ValidationError reports which point or scalar of a statement or proof,
+// "G", "H", "M", "Z", "C" or "R", was rejected. Reason is the sentinel
+// saying why: ErrIncompleteProof if it's missing, ErrInconsistentCurves if
+// it's on a different curve from G, ErrPointOffCurve, or ErrScalarRange.
+type ValidationError struct {
+	Field  string
+	Reason error
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("dleq: %s: %v", e.Field, e.Reason)
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Reason
+}
+
+// A FieldError reports which field of a JSON proof was rejected and why.
+type FieldError struct {
+	Field string
+	Err   error
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("dleq: field %q: %v", e.Field, e.Err)
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,129 @@
+package dleq
+
+import (
+	"crypto"
+	"crypto/elliptic"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"testing"
+)
+
+func TestIsValidationError(t *testing.T) {
+	for _, tc := range []struct {
+		err  error
+		want bool
+	}{
+		{ErrPointOffCurve, true},
+		{ErrIdentityPoint, true},
+		{ErrMalformedProof, true},
+		{ErrUnknownHash, true},
+		{ErrProofTooLarge, true},
+		{ErrChallengeBits, true},
+		{ErrSameChallenge, true},
+		{&FieldError{Field: "G", Err: ErrFieldHex}, true},
+		{fmt.Errorf("decoding: %w", ErrInvalidPoint), true},
+
+		{ErrNonceReuse, false},
+		{ErrSelfVerify, false},
+		{ErrRandomnessUnavailable, false},
+		{ErrStateConsumed, false},
+		{io.ErrUnexpectedEOF, false},
+		{errors.New("dleq: something else"), false},
+		{nil, false},
+	} {
+		if got := IsValidationError(tc.err); got != tc.want {
+			t.Errorf("IsValidationError(%v) = %v, expected %v", tc.err, got, tc.want)
+		}
+	}
+}
+
+func TestValidationErrorsFromAPI(t *testing.T) {
+	G, H, M, Z, x := testStatement(t, elliptic.P256())
+	other, _, _, _, _ := testStatement(t, elliptic.P384())
+
+	_, err := NewProof(crypto.SHA256, other, H, M, Z, x)
+	if !errors.Is(err, ErrInconsistentCurves) || !IsValidationError(err) {
+		t.Errorf("mixed curves: got %v", err)
+	}
+	// An unlinked hash is reported, not a panic from hash.New.
+	_, err = NewProof(crypto.MD4, G, H, M, Z, x)
+	if err != ErrUnknownHash {
+		t.Errorf("unavailable hash: got %v", err)
+	}
+	if _, err := NewProver(crypto.MD4, x).Prove(&Statement{G: G, H: H, M: M, Z: Z}); err != ErrUnknownHash {
+		t.Errorf("unavailable hash in Prover: got %v", err)
+	}
+	if _, err := NewBatchProof(crypto.MD4, G, H, []*Point{M}, []*Point{Z}, x); err != ErrUnknownHash {
+		t.Errorf("unavailable hash in batch: got %v", err)
+	}
+	if err := new(Proof).UnmarshalBinary([]byte{0xff}); !IsValidationError(err) {
+		t.Errorf("malformed proof: got %v", err)
+	}
+	if err := ValidateJSON([]byte(`{"curve": "P-256"}`)); !IsValidationError(err) {
+		t.Errorf("invalid JSON proof: got %v", err)
+	}
+	for _, data := range []string{"x", "", `["not", "an", "object"]`, `{"truncated": `} {
+		err := ValidateJSON([]byte(data))
+		if !errors.Is(err, ErrMalformedProof) || !IsValidationError(err) {
+			t.Errorf("non-JSON input %q: got %v", data, err)
+		}
+		if err := new(Proof).UnmarshalJSON([]byte(data)); !IsValidationError(err) {
+			t.Errorf("non-JSON input %q to UnmarshalJSON: got %v", data, err)
+		}
+	}
+}
+
+func TestValidationErrorFields(t *testing.T) {
+	curve := elliptic.P256()
+	G, H, M, Z, x := testStatement(t, curve)
+	other, _, _, _, _ := testStatement(t, elliptic.P384())
+	offCurve := &Point{Curve: curve, X: big.NewInt(1), Y: big.NewInt(1)}
+
+	for _, tc := range []struct {
+		g, h, m, z *Point
+		field      string
+		reason     error
+	}{
+		{G, nil, M, Z, "H", ErrIncompleteProof},
+		{G, H, M, nil, "Z", ErrIncompleteProof},
+		{G, H, other, Z, "M", ErrInconsistentCurves},
+		{offCurve, H, M, Z, "G", ErrPointOffCurve},
+		{G, H, M, offCurve, "Z", ErrPointOffCurve},
+	} {
+		_, err := NewProof(crypto.SHA256, tc.g, tc.h, tc.m, tc.z, x)
+		var vErr *ValidationError
+		if !errors.As(err, &vErr) {
+			t.Errorf("%s: got %v, expected a *ValidationError", tc.field, err)
+			continue
+		}
+		if vErr.Field != tc.field || vErr.Reason != tc.reason {
+			t.Errorf("got field %q, reason %v; expected %q, %v", vErr.Field, vErr.Reason, tc.field, tc.reason)
+		}
+		if !errors.Is(err, tc.reason) || !IsValidationError(err) {
+			t.Errorf("%s: %v doesn't match its sentinel", tc.field, err)
+		}
+	}
+
+	proof, err := NewProof(crypto.SHA256, G, H, M, Z, x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		field   string
+		perturb func(*Proof)
+	}{
+		{"C", func(pr *Proof) { pr.C = new(big.Int) }},
+		{"C", func(pr *Proof) { pr.C = curve.Params().N }},
+		{"R", func(pr *Proof) { pr.R = curve.Params().N }},
+		{"R", func(pr *Proof) { pr.R = big.NewInt(-1) }},
+	} {
+		bad := *proof
+		tc.perturb(&bad)
+		var vErr *ValidationError
+		if err := bad.VerifyError(); !errors.As(err, &vErr) || vErr.Field != tc.field || vErr.Reason != ErrScalarRange {
+			t.Errorf("%s out of range: got %v", tc.field, err)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package dleq
+
+import (
+	"math/big"
+)
+
+// ExtractWitness is the special-soundness extractor for the protocol. Given
+// two accepting transcripts (a, b, c1, r1) and (a, b, c2, r2) over the same
+// statement and commitments but different challenges, it recovers the
+// witness:
+//
+// r1 - r2 = (s - c1 x) - (s - c2 x) = (c2 - c1) x
+// x = (r1 - r2) / (c2 - c1) (mod q)
+//
+// A Fiat-Shamir prover only ever produces one challenge per commitment, so
+// the two transcripts have to come from rewinding an interactive prover. That
+// a witness falls out at all is the reason a valid proof convinces the
+// verifier that the prover knows x. This is meant for tests and audits;
+// nothing in normal use should hold two such proofs.
+func ExtractWitness(pr1, pr2 *Proof) (*big.Int, error) {
+	if !pr1.IsComplete() || !pr2.IsComplete() {
+		return nil, ErrIncompleteProof
+	}
+	if !pr1.IsSane() || !pr2.IsSane() {
+		return nil, ErrPointOffCurve
+	}
+	if !SameCurve(pr1.G.Curve, pr2.G.Curve) {
+		return nil, ErrInconsistentCurves
+	}
+	if pr1.opts.Convention != pr2.opts.Convention {
+		return nil, ErrStatementMismatch
+	}
+	if !pr1.G.equal(pr2.G) || !pr1.H.equal(pr2.H) || !pr1.M.equal(pr2.M) || !pr1.Z.equal(pr2.Z) {
+		return nil, ErrStatementMismatch
+	}
+	curve := pr1.G.Curve
+	N := curve.Params().N
+
+	// The commitments are implied by (c, r), so the transcripts share a
+	// commitment exactly when these agree.
+	a1, b1 := pr1.commitments()
+	a2, b2 := pr2.commitments()
+	if !a1.equal(a2) || !b1.equal(b2) {
+		return nil, ErrCommitmentMismatch
+	}
+
+	dc := new(big.Int).Sub(pr2.C, pr1.C)
+	dc.Mod(dc, N) // dc = c2 - c1 (mod q)
+	if dc.Sign() == 0 {
+		return nil, ErrSameChallenge
+	}
+
+	x := new(big.Int).Sub(pr1.R, pr2.R)
+	x.Mul(x, dc.ModInverse(dc, N))
+	if pr1.opts.Convention == Additive {
+		// r1 - r2 = (c1 - c2) x
+		x.Neg(x)
+	}
+	x.Mod(x, N)
+	return x, nil
+}
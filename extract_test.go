@@ -0,0 +1,69 @@
+package dleq
+
+import (
+	"crypto"
+	"crypto/elliptic"
+	"crypto/rand"
+	"math/big"
+	"testing"
+)
+
+// rewind runs the interactive protocol twice from the same commitment,
+// answering two different challenges.
+func rewind(t *testing.T, G, H, M, Z *Point, x *big.Int) (*Proof, *Proof) {
+	curve := G.Curve
+	s, _, _, err := commit(curve, G, M, rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, c1, err := randScalar(curve, rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, c2, err := randScalar(curve, rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pr1 := &Proof{G: G, H: H, M: M, Z: Z, C: c1, R: ComputeResponse(s, c1, x, curve.Params().N), params: params{hash: crypto.SHA256}}
+	pr2 := &Proof{G: G, H: H, M: M, Z: Z, C: c2, R: ComputeResponse(s, c2, x, curve.Params().N), params: params{hash: crypto.SHA256}}
+	return pr1, pr2
+}
+
+func TestExtractWitness(t *testing.T) {
+	G, H, M, Z, x := testStatement(t, elliptic.P256())
+	pr1, pr2 := rewind(t, G, H, M, Z, x)
+
+	extracted, err := ExtractWitness(pr1, pr2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if extracted.Cmp(x) != 0 {
+		t.Fatal("extracted the wrong witness")
+	}
+}
+
+func TestExtractWitnessSameChallenge(t *testing.T) {
+	G, H, M, Z, x := testStatement(t, elliptic.P256())
+	proof, err := NewProof(crypto.SHA256, G, H, M, Z, x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ExtractWitness(proof, proof); err != ErrSameChallenge {
+		t.Fatalf("expected ErrSameChallenge, got %v", err)
+	}
+}
+
+func TestExtractWitnessDifferentCommitments(t *testing.T) {
+	G, H, M, Z, x := testStatement(t, elliptic.P256())
+	pr1, err := NewProof(crypto.SHA256, G, H, M, Z, x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pr2, err := NewProof(crypto.SHA256, G, H, M, Z, x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ExtractWitness(pr1, pr2); err != ErrCommitmentMismatch {
+		t.Fatalf("expected ErrCommitmentMismatch, got %v", err)
+	}
+}
@@ -0,0 +1,69 @@
+package dleq
+
+import (
+	"crypto"
+	"encoding/json"
+	"math/big"
+)
+
+// FormatCBT is the proof layout of the challenge bypass token (Privacy Pass)
+// server. A proof is a JSON object of base64 big-endian scalars,
+//
+//	{"R": "...", "C": "..."}
+//
+// and a batch proof wraps that, itself base64, in another object:
+//
+//	{"P": "<base64 of the proof object>"}
+//
+// The statement of a batch proof is the composite statement, which the
+// verifier derives from the batch.
+//
+// Only the layout is implemented from the server's description. The proofs
+// are verified with this package's transcript, and no proof from a real CBT
+// server has been checked against it, so interoperability is unconfirmed.
+const FormatCBT = "cbt"
+
+// VerifyForeign parses a proof produced by another library and verifies it
+// against statement. The error reports parse failures, the bool the verdict.
+func VerifyForeign(format string, data []byte, statement *Statement, hash crypto.Hash) (bool, error) {
+	if err := statement.check(); err != nil {
+		return false, err
+	}
+	var pr *Proof
+	var err error
+	switch format {
+	case FormatCBT:
+		pr, err = parseCBT(data)
+	default:
+		return false, ErrUnknownFormat
+	}
+	if err != nil {
+		return false, err
+	}
+
+	pr.G, pr.H, pr.M, pr.Z = statement.G, statement.H, statement.M, statement.Z
+	pr.hash = hash
+	return pr.Verify(), nil
+}
+
+func parseCBT(data []byte) (*Proof, error) {
+	var fields map[string][]byte
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, ErrMalformedProof
+	}
+	if inner, ok := fields["P"]; ok {
+		fields = nil
+		if err := json.Unmarshal(inner, &fields); err != nil {
+			return nil, ErrMalformedProof
+		}
+	}
+	r, rOK := fields["R"]
+	c, cOK := fields["C"]
+	if !rOK || !cOK {
+		return nil, ErrMalformedProof
+	}
+	return &Proof{
+		R: new(big.Int).SetBytes(r),
+		C: new(big.Int).SetBytes(c),
+	}, nil
+}
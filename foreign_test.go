@@ -0,0 +1,65 @@
+package dleq
+
+import (
+	"crypto"
+	"crypto/elliptic"
+	"math/big"
+	"testing"
+)
+
+// A proof of cbtStatement in the challenge bypass token layout, wrapped in
+// a "P" object as the server wraps batch proofs. It was generated by this
+// package, not captured from a CBT server, so it tests the parsing and
+// nothing about interoperability.
+const cbtVector = `{"P":"eyJDIjoiNnpoQ1RIeGRob3BHN25mUWwxM2NXRlhub0dOZlVrekxsV2hyY0FCYnl0az0iLCJSIjoiMGlwZmRzd3NyeFdjbTlHVXRXUnlrSEc4WGV4ekpENlJZWk8wRVdyMTFDdz0ifQ=="}`
+
+// cbtStatement is G the P-256 base point, M = 3G and x = 0x7654321.
+func cbtStatement() *Statement {
+	curve := elliptic.P256()
+	params := curve.Params()
+	x := big.NewInt(0x7654321).Bytes()
+
+	Mx, My := curve.ScalarBaseMult([]byte{3})
+	Hx, Hy := curve.ScalarBaseMult(x)
+	Zx, Zy := curve.ScalarMult(Mx, My, x)
+	return &Statement{
+		G: &Point{Curve: curve, X: params.Gx, Y: params.Gy},
+		H: &Point{Curve: curve, X: Hx, Y: Hy},
+		M: &Point{Curve: curve, X: Mx, Y: My},
+		Z: &Point{Curve: curve, X: Zx, Y: Zy},
+	}
+}
+
+func TestVerifyForeignCBT(t *testing.T) {
+	ok, err := VerifyForeign(FormatCBT, []byte(cbtVector), cbtStatement(), crypto.SHA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok {
+		t.Fatal("foreign proof was invalid")
+	}
+
+	// The same proof against a different statement.
+	stmt := cbtStatement()
+	stmt.H, stmt.Z = stmt.Z, stmt.H
+	ok, err = VerifyForeign(FormatCBT, []byte(cbtVector), stmt, crypto.SHA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok {
+		t.Fatal("foreign proof verified against the wrong statement")
+	}
+}
+
+func TestVerifyForeignErrors(t *testing.T) {
+	stmt := cbtStatement()
+	if _, err := VerifyForeign("privacy-pass-js", []byte(cbtVector), stmt, crypto.SHA256); err != ErrUnknownFormat {
+		t.Errorf("expected ErrUnknownFormat, got %v", err)
+	}
+	if _, err := VerifyForeign(FormatCBT, []byte(`{"R": "AAAA"}`), stmt, crypto.SHA256); err != ErrMalformedProof {
+		t.Errorf("expected ErrMalformedProof, got %v", err)
+	}
+	if _, err := VerifyForeign(FormatCBT, []byte(`not json`), stmt, crypto.SHA256); err != ErrMalformedProof {
+		t.Errorf("expected ErrMalformedProof, got %v", err)
+	}
+}
@@ -0,0 +1,89 @@
+package dleq
+
+import (
+	"crypto/elliptic"
+	"math/big"
+)
+
+// A GroupElement is the minimal interface a sigma-protocol needs from an
+// element of a prime-order group. It lets protocol code be written without
+// depending on crypto/elliptic. Elements of different groups don't mix:
+// Add panics if handed one, and Equal reports false.
+type GroupElement interface {
+	Add(GroupElement) GroupElement
+	ScalarMul(k *big.Int) GroupElement
+	Marshal() []byte
+	Equal(GroupElement) bool
+	IsIdentity() bool
+}
+
+var _ GroupElement = (*Point)(nil)
+
+// A Group is a prime-order group that generic proofs can be made in. There
+// are two backends: CurveGroup adapts a crypto/elliptic curve, whose elements
+// are *Point, and Ristretto255 is the RFC 9496 group, whose elements are
+// *RistrettoElement. Transcripts hash elements as their Marshal encodings,
+// so a proof over CurveGroup(curve) is interchangeable with a Proof.
+type Group interface {
+	Order() *big.Int
+	Generator() GroupElement
+
+	// Contains reports whether e is a valid element of this group.
+	Contains(e GroupElement) bool
+}
+
+type curveGroup struct {
+	curve elliptic.Curve
+}
+
+// CurveGroup returns the group of points on curve.
+func CurveGroup(curve elliptic.Curve) Group {
+	return curveGroup{curve}
+}
+
+func (g curveGroup) Order() *big.Int { return new(big.Int).Set(g.curve.Params().N) }
+
+func (g curveGroup) Generator() GroupElement {
+	return basePoint(g.curve)
+}
+
+func (g curveGroup) Contains(e GroupElement) bool {
+	p, ok := e.(*Point)
+	return ok && p != nil && p.X != nil && p.Y != nil && SameCurve(p.Curve, g.curve) && p.IsOnCurve()
+}
+
+// asPoint converts e to a point on the same curve as p, or returns nil.
+func (p *Point) asPoint(e GroupElement) *Point {
+	q, ok := e.(*Point)
+	if !ok || q == nil || !SameCurve(p.Curve, q.Curve) {
+		return nil
+	}
+	return q
+}
+
+// Add returns p + e. As in crypto/elliptic, the identity is (0, 0).
+func (p *Point) Add(e GroupElement) GroupElement {
+	q := p.asPoint(e)
+	if q == nil {
+		panic("dleq: adding elements of different groups")
+	}
+	x, y := p.Curve.Add(p.X, p.Y, q.X, q.Y)
+	return &Point{Curve: p.Curve, X: x, Y: y}
+}
+
+// ScalarMul returns kP, with k reduced mod the group order.
+func (p *Point) ScalarMul(k *big.Int) GroupElement {
+	k = new(big.Int).Mod(k, p.Curve.Params().N)
+	x, y := p.Curve.ScalarMult(p.X, p.Y, k.Bytes())
+	return &Point{Curve: p.Curve, X: x, Y: y}
+}
+
+// Equal compares p with e in constant time.
+func (p *Point) Equal(e GroupElement) bool {
+	q := p.asPoint(e)
+	return q != nil && p.equal(q)
+}
+
+func (p *Point) IsIdentity() bool {
+	return p.X.Sign() == 0 && p.Y.Sign() == 0
+}
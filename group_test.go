@@ -0,0 +1,86 @@
+package dleq
+
+import (
+	"crypto"
+	"crypto/elliptic"
+	"math/big"
+	"testing"
+)
+
+func TestPointGroupElement(t *testing.T) {
+	curve := elliptic.P256()
+	params := curve.Params()
+	var G GroupElement = &Point{Curve: curve, X: params.Gx, Y: params.Gy}
+
+	two := G.ScalarMul(big.NewInt(2))
+	if !G.Add(G).Equal(two) {
+		t.Error("G + G != 2G")
+	}
+	if G.Equal(two) {
+		t.Error("G == 2G")
+	}
+	three := G.ScalarMul(big.NewInt(3))
+	if !two.Add(G).Equal(three) {
+		t.Error("2G + G != 3G")
+	}
+
+	// Scalars are reduced, so NG is the identity and (N+1)G is G.
+	if !G.ScalarMul(params.N).IsIdentity() {
+		t.Error("NG is not the identity")
+	}
+	if G.IsIdentity() {
+		t.Error("G is the identity")
+	}
+	if !G.ScalarMul(new(big.Int).Add(params.N, big.NewInt(1))).Equal(G) {
+		t.Error("(N+1)G != G")
+	}
+	if !G.Add(G.ScalarMul(params.N)).Equal(G) {
+		t.Error("G + O != G")
+	}
+
+	expected := elliptic.Marshal(curve, params.Gx, params.Gy)
+	if string(G.Marshal()) != string(expected) {
+		t.Error("Marshal is not the SEC 1 uncompressed encoding")
+	}
+}
+
+func TestPointGroupElementMixedCurves(t *testing.T) {
+	p256, p384 := elliptic.P256().Params(), elliptic.P384().Params()
+	G := &Point{Curve: elliptic.P256(), X: p256.Gx, Y: p256.Gy}
+	other := &Point{Curve: elliptic.P384(), X: p384.Gx, Y: p384.Gy}
+
+	if G.Equal(other) {
+		t.Error("points on different curves compared equal")
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("adding points on different curves didn't panic")
+		}
+	}()
+	G.Add(other)
+}
+
+func TestGroupProofOverCurve(t *testing.T) {
+	// The generic proof over a curve has Proof's transcript, so either form
+	// verifies the other's (C, R).
+	G, H, M, Z, x := testStatement(t, elliptic.P256())
+	gp, err := NewGroupProof(crypto.SHA256, CurveGroup(elliptic.P256()), G, H, M, Z, x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !gp.Verify() {
+		t.Fatal("group proof was invalid")
+	}
+	proof := &Proof{G: G, H: H, M: M, Z: Z, C: gp.C, R: gp.R, params: params{hash: crypto.SHA256}}
+	if !proof.Verify() {
+		t.Fatal("group proof did not verify as a Proof")
+	}
+
+	other, _, _, _, _ := testStatement(t, elliptic.P384())
+	if _, err := NewGroupProof(crypto.SHA256, CurveGroup(elliptic.P256()), other, H, M, Z, x); err != ErrNotInGroup {
+		t.Fatalf("expected ErrNotInGroup, got %v", err)
+	}
+	if _, err := NewGroupProof(crypto.SHA256, Ristretto255, G, H, M, Z, x); err != ErrNotInGroup {
+		t.Fatalf("expected ErrNotInGroup, got %v", err)
+	}
+}
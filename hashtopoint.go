@@ -0,0 +1,69 @@
+package dleq
+
+import (
+	"crypto"
+	"crypto/elliptic"
+	"encoding/binary"
+)
+
+// HashToPointWithCounter derives a point on curve from data by
+// try-and-increment, returning it along with the counter that produced it.
+// For counter = 0, 1, ... the candidate X coordinate is the hash of
+// (counter, block, data), extended block by block to the field length and
+// masked to its bit size, and the first candidate that is on the curve is
+// taken with even Y. The result depends only on its inputs, and nobody knows
+// its discrete log to any other point, which is what generators for a
+// statement need.
+//
+// Each candidate succeeds with probability about 1/2, so the counter is
+// small. It leaks nothing about data beyond what the point does, and is
+// worth recording alongside test vectors so that a derivation can be
+// checked step by step.
+func HashToPointWithCounter(curve elliptic.Curve, hash crypto.Hash, data []byte) (*Point, int, error) {
+	return hashToPoint(curve, hash, data, -1)
+}
+
+// HashToPointWithBound is HashToPointWithCounter giving up with
+// ErrHashToPointBound if no counter up to maxCounter yields a point, for
+// callers that want the running time bounded.
+func HashToPointWithBound(curve elliptic.Curve, hash crypto.Hash, data []byte, maxCounter int) (*Point, int, error) {
+	if maxCounter < 0 {
+		return nil, 0, ErrHashToPointBound
+	}
+	return hashToPoint(curve, hash, data, maxCounter)
+}
+
+// hashToPoint tries counters up to limit, or without end if limit is
+// negative.
+func hashToPoint(curve elliptic.Curve, hash crypto.Hash, data []byte, limit int) (*Point, int, error) {
+	if !hash.Available() {
+		return nil, 0, ErrUnknownHash
+	}
+	bitSize := curve.Params().BitSize
+	byteLen := (bitSize + 7) / 8
+	candidate := make([]byte, 1+byteLen)
+	candidate[0] = 0x02
+	x := make([]byte, 0, byteLen+hash.Size())
+
+	var prefix [8]byte
+	for counter := 0; limit < 0 || counter <= limit; counter++ {
+		x = x[:0]
+		for block := uint32(0); len(x) < byteLen; block++ {
+			binary.BigEndian.PutUint32(prefix[:4], uint32(counter))
+			binary.BigEndian.PutUint32(prefix[4:], block)
+			H := hash.New()
+			H.Write(prefix[:])
+			H.Write(data)
+			x = H.Sum(x)
+		}
+		copy(candidate[1:], x)
+		candidate[1] &= mask[bitSize%8]
+
+		// UnmarshalCompressed rejects X outside the field and X with no
+		// square root, which is every failed attempt.
+		if px, py := elliptic.UnmarshalCompressed(curve, candidate); px != nil {
+			return &Point{Curve: curve, X: px, Y: py}, counter, nil
+		}
+	}
+	return nil, 0, ErrHashToPointBound
+}
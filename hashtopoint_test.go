@@ -0,0 +1,69 @@
+package dleq
+
+import (
+	"crypto"
+	"crypto/elliptic"
+	"fmt"
+	"testing"
+)
+
+func TestHashToPointWithCounter(t *testing.T) {
+	for _, curve := range curves[1:] {
+		data := []byte("dleq generator " + curve.Params().Name)
+		p, counter, err := HashToPointWithCounter(curve, crypto.SHA256, data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !p.IsOnCurve() {
+			t.Fatalf("%s: point is off the curve", curve.Params().Name)
+		}
+		if p.Y.Bit(0) != 0 {
+			t.Fatalf("%s: point has odd Y", curve.Params().Name)
+		}
+		again, againCounter, err := HashToPointWithCounter(curve, crypto.SHA256, data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !again.equal(p) || againCounter != counter {
+			t.Fatalf("%s: derivation was not deterministic", curve.Params().Name)
+		}
+		other, _, err := HashToPointWithCounter(curve, crypto.SHA256, append(data, 0))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if other.equal(p) {
+			t.Fatalf("%s: different inputs gave the same point", curve.Params().Name)
+		}
+	}
+}
+
+func TestHashToPointWithBound(t *testing.T) {
+	curve := elliptic.P256()
+
+	// Find an input that needs at least one increment.
+	var data []byte
+	var p *Point
+	var counter int
+	for i := 0; counter == 0; i++ {
+		data = []byte(fmt.Sprintf("input %d", i))
+		var err error
+		p, counter, err = HashToPointWithCounter(curve, crypto.SHA256, data)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	bounded, boundedCounter, err := HashToPointWithBound(curve, crypto.SHA256, data, counter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bounded.equal(p) || boundedCounter != counter {
+		t.Fatal("bounded derivation gave a different point")
+	}
+	if _, _, err := HashToPointWithBound(curve, crypto.SHA256, data, counter-1); err != ErrHashToPointBound {
+		t.Fatalf("expected ErrHashToPointBound, got %v", err)
+	}
+	if _, _, err := HashToPointWithCounter(curve, crypto.MD4, data); err != ErrUnknownHash {
+		t.Fatalf("expected ErrUnknownHash, got %v", err)
+	}
+}
@@ -0,0 +1,90 @@
+package dleq
+
+import (
+	"crypto"
+	"crypto/elliptic"
+	"encoding/hex"
+	"math/big"
+)
+
+// ProveHex is a one-call path from string inputs to a string proof, meant
+// for scripting and experiments. The generators g and m are hex SEC 1 points,
+// uncompressed or compressed, on whichever registered curve their length
+// implies; x is a hex scalar of any length, as big.Int.Text(16) or %x write
+// it. It derives h = g^x and z = m^x and returns the proof in the binary
+// format, hex encoded. Malformed inputs are reported as a *FieldError naming
+// "g", "m" or "x".
+func ProveHex(hash crypto.Hash, gHex, mHex, xHex string) (string, error) {
+	g, err := decodeHexPoint("g", gHex, nil)
+	if err != nil {
+		return "", err
+	}
+	curve := g.Curve
+	m, err := decodeHexPoint("m", mHex, curve)
+	if err != nil {
+		return "", err
+	}
+	x, ok := new(big.Int).SetString(xHex, 16)
+	if !ok {
+		return "", &FieldError{Field: "x", Err: ErrFieldHex}
+	}
+	h, err := PublicKey(g, x)
+	if err != nil {
+		return "", &FieldError{Field: "x", Err: err}
+	}
+	z, err := PublicKey(m, x)
+	if err != nil {
+		return "", &FieldError{Field: "x", Err: err}
+	}
+
+	proof, err := NewProof(hash, g, h, m, z, x)
+	if err != nil {
+		return "", err
+	}
+	data, err := proof.MarshalBinary()
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(data), nil
+}
+
+// VerifyHex decodes a hex proof in the binary format, as made by ProveHex,
+// and verifies it. The error reports malformed input, the bool the verdict.
+func VerifyHex(proofHex string) (bool, error) {
+	if hex.DecodedLen(len(proofHex)) > DefaultMaxProofSize {
+		return false, ErrProofTooLarge
+	}
+	data, err := hex.DecodeString(proofHex)
+	if err != nil {
+		return false, &FieldError{Field: "proof", Err: ErrFieldHex}
+	}
+	proof := new(Proof)
+	if err := proof.UnmarshalBinary(data); err != nil {
+		return false, err
+	}
+	return proof.Verify(), nil
+}
+
+// decodeHexPoint decodes a hex point on curve, or if curve is nil on the
+// registered curve whose encoding has that length.
+func decodeHexPoint(field, s string, curve elliptic.Curve) (*Point, error) {
+	data, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, &FieldError{Field: field, Err: ErrFieldHex}
+	}
+	candidates := curves[1:]
+	if curve != nil {
+		candidates = []elliptic.Curve{curve}
+	}
+	for _, c := range candidates {
+		if !pointLength(c, len(data)) {
+			continue
+		}
+		p, err := decodePoint(c, data)
+		if err != nil {
+			return nil, &FieldError{Field: field, Err: err}
+		}
+		return p, nil
+	}
+	return nil, &FieldError{Field: field, Err: ErrFieldLength}
+}
@@ -0,0 +1,78 @@
+package dleq
+
+import (
+	"crypto"
+	"crypto/elliptic"
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+func TestProveHex(t *testing.T) {
+	for _, curve := range []elliptic.Curve{elliptic.P256(), elliptic.P384()} {
+		G, _, M, _, x := testStatement(t, curve)
+		gHex := hex.EncodeToString(G.Marshal())
+		mCompressed, err := M.MarshalEncoding(Compressed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		mHex := hex.EncodeToString(mCompressed)
+
+		proofHex, err := ProveHex(crypto.SHA256, gHex, mHex, x.Text(16))
+		if err != nil {
+			t.Fatal(err)
+		}
+		ok, err := VerifyHex(proofHex)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ok {
+			t.Fatalf("%s: proof was invalid", curve.Params().Name)
+		}
+	}
+
+	// Odd-length hex, as Text(16) writes for a scalar with a short top byte.
+	G, _, M, _, _ := testStatement(t, elliptic.P256())
+	proofHex, err := ProveHex(crypto.SHA256, hex.EncodeToString(G.Marshal()), hex.EncodeToString(M.Marshal()), "abc")
+	if err != nil {
+		t.Fatalf("odd-length witness: %v", err)
+	}
+	if ok, err := VerifyHex(proofHex); err != nil || !ok {
+		t.Fatalf("odd-length witness: proof was invalid: %v", err)
+	}
+}
+
+func TestProveHexErrors(t *testing.T) {
+	G, _, M, _, _ := testStatement(t, elliptic.P256())
+	gHex := hex.EncodeToString(G.Marshal())
+	mHex := hex.EncodeToString(M.Marshal())
+	other, _, _, _, _ := testStatement(t, elliptic.P384())
+	otherHex := hex.EncodeToString(other.Marshal())
+
+	tests := []struct {
+		name    string
+		g, m, x string
+		field   string
+		err     error
+	}{
+		{"bad hex", "zz" + gHex[2:], mHex, "01", "g", ErrFieldHex},
+		{"odd length", gHex, mHex[1:], "01", "m", ErrFieldHex},
+		{"unknown length", gHex[:20], mHex, "01", "g", ErrFieldLength},
+		{"mixed curves", gHex, otherHex, "01", "m", ErrFieldLength},
+		{"zero witness", gHex, mHex, "00", "x", ErrScalarRange},
+		{"bad witness", gHex, mHex, "x", "x", ErrFieldHex},
+		{"empty witness", gHex, mHex, "", "x", ErrFieldHex},
+		{"negative witness", gHex, mHex, "-1", "x", ErrScalarRange},
+	}
+	for _, tt := range tests {
+		_, err := ProveHex(crypto.SHA256, tt.g, tt.m, tt.x)
+		var fieldErr *FieldError
+		if !errors.As(err, &fieldErr) || fieldErr.Field != tt.field || !errors.Is(err, tt.err) {
+			t.Errorf("%s: got %v", tt.name, err)
+		}
+	}
+
+	if _, err := VerifyHex("nothex"); err == nil {
+		t.Error("VerifyHex accepted malformed hex")
+	}
+}
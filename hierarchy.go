@@ -0,0 +1,54 @@
+package dleq
+
+import (
+	"crypto"
+	crand "crypto/rand"
+	"math/big"
+)
+
+// NewChildProof proves statement with the parent proof's C and R folded
+// into the transcript, so that the child only verifies alongside that
+// parent. A parent can have any number of children, each of which can be
+// the parent of more, which makes a tree where a chain would be a line.
+//
+// Like a context, the parent binding is not serialized: the child proof
+// returned here verifies as it is, but a decoded one has to be checked with
+// VerifyHierarchy.
+func NewChildProof(parent *Proof, hash crypto.Hash, statement *Statement, x *big.Int) (*Proof, error) {
+	binding, err := parentBinding(parent)
+	if err != nil {
+		return nil, err
+	}
+	proof := newProof(hash, statement, nil)
+	proof.parent = binding
+	if _, _, err := proof.prove(x, crand.Reader); err != nil {
+		return nil, err
+	}
+	return proof, nil
+}
+
+// VerifyHierarchy verifies parent, and child as a child of parent. It
+// checks one edge of a tree; a parent that is itself a child has to be
+// verified against its own parent in turn.
+func VerifyHierarchy(parent, child *Proof) bool {
+	binding, err := parentBinding(parent)
+	if err != nil || !parent.Verify() {
+		return false
+	}
+	bound := *child
+	bound.parent = binding
+	return bound.Verify()
+}
+
+// parentBinding is the transcript value binding a child to parent: its C
+// and R at the fixed length of the parent's scalars.
+func parentBinding(parent *Proof) ([]byte, error) {
+	if parent == nil {
+		return nil, ErrIncompleteProof
+	}
+	if err := parent.verifiable(); err != nil {
+		return nil, err
+	}
+	curve := parent.G.Curve
+	return append(scalarBytes(curve, parent.C), scalarBytes(curve, parent.R)...), nil
+}
@@ -0,0 +1,74 @@
+package dleq
+
+import (
+	"crypto"
+	"crypto/elliptic"
+	"math/big"
+	"testing"
+)
+
+func TestChildProofs(t *testing.T) {
+	curve := elliptic.P256()
+	newStatement := func() (*Statement, *big.Int) {
+		G, H, M, Z, x := testStatement(t, curve)
+		return &Statement{G: G, H: H, M: M, Z: Z}, x
+	}
+	stmt, x := newStatement()
+	parent, err := NewProof(crypto.SHA256, stmt.G, stmt.H, stmt.M, stmt.Z, x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stmt, x = newStatement()
+	other, err := NewProof(crypto.SHA256, stmt.G, stmt.H, stmt.M, stmt.Z, x)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stmt1, x1 := newStatement()
+	stmt2, x2 := newStatement()
+	child1, err := NewChildProof(parent, crypto.SHA256, stmt1, x1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	child2, err := NewChildProof(parent, crypto.SHA256, stmt2, x2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, child := range []*Proof{child1, child2} {
+		if !child.Verify() {
+			t.Errorf("child %d was invalid as built", i+1)
+		}
+		if !VerifyHierarchy(parent, child) {
+			t.Errorf("child %d was invalid under its parent", i+1)
+		}
+		if VerifyHierarchy(other, child) {
+			t.Errorf("child %d verified under another parent", i+1)
+		}
+		if VerifyHierarchy(child1, child) {
+			t.Errorf("child %d verified under a sibling", i+1)
+		}
+	}
+
+	// The binding isn't serialized, so a decoded child needs its parent.
+	data, err := child1.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded := new(Proof)
+	if err := decoded.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Verify() || !VerifyHierarchy(parent, decoded) {
+		t.Fatal("decoded child did not need its parent")
+	}
+
+	// A parent that doesn't verify fails the whole edge.
+	broken := *parent
+	broken.R = new(big.Int).Add(parent.R, big.NewInt(1))
+	if VerifyHierarchy(&broken, child1) {
+		t.Fatal("verified a child under a broken parent")
+	}
+	if _, err := NewChildProof(&Proof{}, crypto.SHA256, stmt1, x1); err != ErrIncompleteProof {
+		t.Fatalf("incomplete parent: expected ErrIncompleteProof, got %v", err)
+	}
+}
@@ -0,0 +1,24 @@
+package dleq
+
+import (
+	"crypto"
+	crand "crypto/rand"
+	"math/big"
+)
+
+// NewHybridProof is NewProof with the challenge derived from two hash
+// functions at once, c = H1(transcript) || H2(transcript) reduced mod q,
+// hedging against one of them being broken. Both hash identifiers are kept
+// with the proof and Verify recomputes both. It costs one extra hash of the
+// transcript on each side.
+func NewHybridProof(h1, h2 crypto.Hash, g, h, m, z *Point, x *big.Int) (*Proof, error) {
+	if !h1.Available() || !h2.Available() {
+		return nil, ErrUnknownHash
+	}
+	proof := newProof(h1, &Statement{G: g, H: h, M: m, Z: z}, nil)
+	proof.hash2 = h2
+	if _, _, err := proof.prove(x, crand.Reader); err != nil {
+		return nil, err
+	}
+	return proof, nil
+}
@@ -0,0 +1,73 @@
+package dleq
+
+import (
+	"crypto"
+	"crypto/elliptic"
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+	"encoding/json"
+	"math/big"
+	"testing"
+)
+
+func TestHybridProof(t *testing.T) {
+	G, H, M, Z, x := testStatement(t, elliptic.P256())
+	proof, err := NewHybridProof(crypto.SHA256, crypto.SHA512, G, H, M, Z, x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !proof.Verify() {
+		t.Fatal("proof was invalid")
+	}
+
+	// Each hash matters: changing or dropping either breaks the proof.
+	for _, hashes := range [][2]crypto.Hash{
+		{crypto.SHA384, crypto.SHA512},
+		{crypto.SHA256, crypto.SHA384},
+		{crypto.SHA256, 0},
+	} {
+		other := *proof
+		other.hash, other.hash2 = hashes[0], hashes[1]
+		if other.Verify() {
+			t.Errorf("proof verified under hashes %v", hashes)
+		}
+	}
+
+	tampered := *proof
+	tampered.R = new(big.Int).Add(proof.R, big.NewInt(1))
+	if tampered.Verify() {
+		t.Fatal("tampered proof verified")
+	}
+}
+
+func TestHybridProofSerialization(t *testing.T) {
+	G, H, M, Z, x := testStatement(t, elliptic.P256())
+	proof, err := NewHybridProof(crypto.SHA256, crypto.SHA512, G, H, M, Z, x)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := proof.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded := new(Proof)
+	if err := decoded.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.hash2 != crypto.SHA512 || !decoded.Verify() {
+		t.Fatal("binary round trip failed")
+	}
+
+	data, err = json.Marshal(proof)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded = new(Proof)
+	if err := json.Unmarshal(data, decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.hash2 != crypto.SHA512 || !decoded.Verify() {
+		t.Fatal("JSON round trip failed")
+	}
+}
@@ -0,0 +1,19 @@
+package dleq
+
+import "crypto/sha256"
+
+// ID is the SHA-256 of the proof's MarshalBinary encoding, a compact key
+// for caching, deduplicating and logging proofs. Proofs with the same
+// points, scalars and serialized parameters have the same ID, whatever
+// encodings they were decoded from; any other difference changes it. Values
+// that aren't serialized, such as a context or a parent binding, don't
+// enter it.
+//
+// A proof that can't be serialized has the zero ID.
+func (pr *Proof) ID() [32]byte {
+	data, err := pr.MarshalBinary()
+	if err != nil {
+		return [32]byte{}
+	}
+	return sha256.Sum256(data)
+}
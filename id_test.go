@@ -0,0 +1,57 @@
+package dleq
+
+import (
+	"crypto"
+	"crypto/elliptic"
+	"math/big"
+	"testing"
+)
+
+func TestProofID(t *testing.T) {
+	G, H, M, Z, x := testStatement(t, elliptic.P256())
+	proof, err := NewProof(crypto.SHA256, G, H, M, Z, x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := proof.ID()
+	if id == ([32]byte{}) {
+		t.Fatal("complete proof had the zero ID")
+	}
+
+	// A copy decoded from compressed points is the same proof.
+	data, err := proof.MarshalBinaryEncoding(Compressed, Compressed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded := new(Proof)
+	if err := decoded.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.ID() != id {
+		t.Fatal("equal proofs had different IDs")
+	}
+
+	other, err := NewProof(crypto.SHA256, G, H, M, Z, x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	perturbed := map[string]func(*Proof){
+		"G":    func(pr *Proof) { pr.G = M },
+		"H":    func(pr *Proof) { pr.H = Z },
+		"C":    func(pr *Proof) { pr.C = new(big.Int).Add(proof.C, big.NewInt(1)) },
+		"R":    func(pr *Proof) { pr.R = new(big.Int).Add(proof.R, big.NewInt(1)) },
+		"hash": func(pr *Proof) { pr.hash = crypto.SHA384 },
+		"new":  func(pr *Proof) { *pr = *other },
+	}
+	for name, perturb := range perturbed {
+		changed := *proof
+		perturb(&changed)
+		if changed.ID() == id {
+			t.Errorf("%s: perturbed proof kept the ID", name)
+		}
+	}
+
+	if new(Proof).ID() != ([32]byte{}) {
+		t.Error("incomplete proof had a nonzero ID")
+	}
+}
@@ -0,0 +1,21 @@
+package dleq
+
+import (
+	"crypto"
+	"math/big"
+)
+
+// ProveIssuance proves, as a Privacy Pass issuer, that every signed token
+// W_i = k*T_i was made with the key behind the public key Y = k*G. It is a
+// batch proof over (G, Y, [T], [W]): one DLEQ on composites of the tokens,
+// with a single challenge however many tokens are issued.
+func ProveIssuance(hash crypto.Hash, g, y *Point, k *big.Int, tokens, signed []*Point) (*BatchProof, error) {
+	return NewBatchProof(hash, g, y, tokens, signed, k)
+}
+
+// VerifyIssuance checks an issuance proof for the given key and the tokens
+// the client blinded and got back. The proof must be about exactly these
+// points; a valid proof over some other batch is rejected.
+func VerifyIssuance(g, y *Point, tokens, signed []*Point, pr *BatchProof) bool {
+	return pr != nil && pr.isAbout(g, y, tokens, signed) && pr.Verify()
+}
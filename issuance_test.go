@@ -0,0 +1,99 @@
+package dleq
+
+import (
+	"crypto"
+	"crypto/elliptic"
+	"crypto/rand"
+	"math/big"
+	"testing"
+)
+
+// testIssuance makes an issuer key (G, Y = kG) and n blinded tokens T_i
+// signed as W_i = kT_i.
+func testIssuance(t *testing.T, n int) (G, Y *Point, k *big.Int, tokens, signed []*Point) {
+	curve := elliptic.P256()
+	G, Y, _, _, k = testStatement(t, curve)
+	for i := 0; i < n; i++ {
+		_, Tx, Ty, err := elliptic.GenerateKey(curve, rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		Wx, Wy := curve.ScalarMult(Tx, Ty, k.Bytes())
+		tokens = append(tokens, &Point{Curve: curve, X: Tx, Y: Ty})
+		signed = append(signed, &Point{Curve: curve, X: Wx, Y: Wy})
+	}
+	return G, Y, k, tokens, signed
+}
+
+func TestIssuance(t *testing.T) {
+	G, Y, k, tokens, signed := testIssuance(t, 10)
+	proof, err := ProveIssuance(crypto.SHA256, G, Y, k, tokens, signed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !VerifyIssuance(G, Y, tokens, signed, proof) {
+		t.Fatal("issuance proof was invalid")
+	}
+
+	// The client holds its own copy of the batch; a proof over any other
+	// batch, key or ordering must not pass for it.
+	other, _, _, _, _ := testIssuance(t, 0)
+	if VerifyIssuance(G, other, tokens, signed, proof) {
+		t.Fatal("verified under the wrong public key")
+	}
+	swapped := append([]*Point{}, signed...)
+	swapped[0], swapped[1] = swapped[1], swapped[0]
+	if VerifyIssuance(G, Y, tokens, swapped, proof) {
+		t.Fatal("verified against reordered signed tokens")
+	}
+	if VerifyIssuance(G, Y, tokens[1:], signed[1:], proof) {
+		t.Fatal("verified against a partial batch")
+	}
+}
+
+func TestIssuanceWrongKey(t *testing.T) {
+	// The issuer signs one token with a different key, tagging that client.
+	G, Y, k, tokens, signed := testIssuance(t, 10)
+	curve := G.Curve
+	_, Wx, Wy, err := elliptic.GenerateKey(curve, rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signed[3] = &Point{Curve: curve, X: Wx, Y: Wy}
+
+	proof, err := ProveIssuance(crypto.SHA256, G, Y, k, tokens, signed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if VerifyIssuance(G, Y, tokens, signed, proof) {
+		t.Fatal("verified issuance under two keys")
+	}
+}
+
+func TestIssuanceRejectsBadTokens(t *testing.T) {
+	G, Y, k, tokens, signed := testIssuance(t, 3)
+	curve := G.Curve
+	offCurve := &Point{Curve: curve, X: big.NewInt(1), Y: big.NewInt(1)}
+	other, _, _, _, _ := testStatement(t, elliptic.P384())
+
+	for _, tc := range []struct {
+		name  string
+		token *Point
+		err   error
+	}{
+		{"off-curve token", offCurve, ErrPointOffCurve},
+		{"nil token", nil, ErrPointOffCurve},
+		{"token on another curve", other, ErrInconsistentCurves},
+	} {
+		bad := append([]*Point{}, tokens...)
+		bad[1] = tc.token
+		if _, err := ProveIssuance(crypto.SHA256, G, Y, k, bad, signed); err != tc.err {
+			t.Errorf("%s: got %v, expected %v", tc.name, err, tc.err)
+		}
+	}
+	bad := append([]*Point{}, signed...)
+	bad[2] = offCurve
+	if _, err := ProveIssuance(crypto.SHA256, G, Y, k, tokens, bad); err != ErrPointOffCurve {
+		t.Errorf("off-curve signed token: got %v", err)
+	}
+}
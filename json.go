@@ -0,0 +1,390 @@
+package dleq
+
+import (
+	"crypto"
+	"crypto/elliptic"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+)
+
+// A JSON proof is an object of hex strings along with the curve and hash
+// names, all of which are required:
+//
+//	{"curve": "P-256", "hash": "SHA-256",
+//	 "G": "04...", "H": "04...", "M": "04...", "Z": "04...",
+//	 "C": "...", "R": "..."}
+//
+// Points are SEC 1 encoded, either uncompressed or compressed. Scalars are
+// padded to the length of the group order and are big-endian unless the
+// optional "endian" field is "little". The optional "response" field is
+// "additive" for proofs using that convention. Proofs anchored to a beacon
+// carry it as "beaconRound", a decimal string, and "beaconValue", hex,
+// hybrid proofs name their second hash in "hash2", and proofs with a
+// truncated challenge give its length in "challengeBits", a decimal string.
+// C is padded to the group order either way. Proofs whose transcript binds
+// the curve and hash have "bindParameters": "true", and proofs hashing the
+// points in another order name it in "transcriptOrder", one of "GMHZ" and
+// "MZ". Salted proofs carry the salt, hex, in "salt".
+type jsonProof struct {
+	Curve    string `json:"curve"`
+	Hash     string `json:"hash"`
+	Hash2    string `json:"hash2,omitempty"`
+	Endian   string `json:"endian,omitempty"`
+	Response string `json:"response,omitempty"`
+	G        string `json:"G"`
+	H        string `json:"H"`
+	M        string `json:"M"`
+	Z        string `json:"Z"`
+	C        string `json:"C"`
+	R        string `json:"R"`
+
+	BeaconRound     string `json:"beaconRound,omitempty"`
+	BeaconValue     string `json:"beaconValue,omitempty"`
+	ChallengeBits   string `json:"challengeBits,omitempty"`
+	BindParameters  string `json:"bindParameters,omitempty"`
+	TranscriptOrder string `json:"transcriptOrder,omitempty"`
+	Salt            string `json:"salt,omitempty"`
+}
+
+var (
+	jsonPointFields  = []string{"G", "H", "M", "Z"}
+	jsonScalarFields = []string{"C", "R"}
+)
+
+func curveByName(name string) (elliptic.Curve, bool) {
+	for _, curve := range curves[1:] {
+		if curve.Params().Name == name {
+			return curve, true
+		}
+	}
+	return nil, false
+}
+
+var transcriptOrderNames = map[TranscriptOrder]string{
+	TranscriptGHMZ: "GHMZ",
+	TranscriptGMHZ: "GMHZ",
+	TranscriptMZ:   "MZ",
+}
+
+func transcriptOrderByName(name string) (TranscriptOrder, bool) {
+	for order, n := range transcriptOrderNames {
+		if n == name {
+			return order, true
+		}
+	}
+	return 0, false
+}
+
+func hashByName(name string) (crypto.Hash, bool) {
+	for h := crypto.MD4; h <= crypto.BLAKE2b_512; h++ {
+		if h.String() == name && h.Available() {
+			return h, true
+		}
+	}
+	return 0, false
+}
+
+func (pr *Proof) MarshalJSON() ([]byte, error) {
+	if !pr.IsComplete() {
+		return nil, ErrIncompleteProof
+	}
+	curve := pr.G.Curve
+	if _, ok := curveID(curve); !ok {
+		return nil, ErrUnknownCurve
+	}
+	if !scalarInRange(curve, pr.C) || !scalarInRange(curve, pr.R) {
+		return nil, ErrMalformedProof
+	}
+	if pr.opts.checkChallengeBits(curve) != nil || !pr.opts.challengeFits(pr.C) {
+		return nil, ErrMalformedProof
+	}
+	if err := pr.opts.checkOrder(); err != nil {
+		return nil, err
+	}
+	endian := pr.opts.Endianness
+	out := &jsonProof{
+		Curve: curve.Params().Name,
+		Hash:  pr.hash.String(),
+		G:     hex.EncodeToString(pr.G.Marshal()),
+		H:     hex.EncodeToString(pr.H.Marshal()),
+		M:     hex.EncodeToString(pr.M.Marshal()),
+		Z:     hex.EncodeToString(pr.Z.Marshal()),
+		C:     hex.EncodeToString(endian.encode(curve, pr.C)),
+		R:     hex.EncodeToString(endian.encode(curve, pr.R)),
+	}
+	if endian == LittleEndian {
+		out.Endian = "little"
+	}
+	if pr.opts.Convention == Additive {
+		out.Response = "additive"
+	}
+	if pr.hash2 != 0 {
+		out.Hash2 = pr.hash2.String()
+	}
+	if pr.beacon != nil {
+		out.BeaconRound = strconv.FormatUint(pr.beacon.Round, 10)
+		out.BeaconValue = hex.EncodeToString(pr.beacon.Value)
+	}
+	if pr.opts.ChallengeBits != 0 {
+		out.ChallengeBits = strconv.Itoa(pr.opts.ChallengeBits)
+	}
+	if pr.opts.BindParameters {
+		out.BindParameters = "true"
+	}
+	if pr.opts.TranscriptOrder != TranscriptGHMZ {
+		out.TranscriptOrder = transcriptOrderNames[pr.opts.TranscriptOrder]
+	}
+	if pr.salt != nil {
+		if len(pr.salt) != SaltSize {
+			return nil, ErrMalformedProof
+		}
+		out.Salt = hex.EncodeToString(pr.salt)
+	}
+	return json.Marshal(out)
+}
+
+// ValidateJSON checks a JSON proof against the expected schema without
+// building a Proof: every field must be present, the curve and hash must be
+// known, and each point and scalar must be hex of a length that fits the
+// curve. Failures are reported as a *FieldError naming the offending field.
+func ValidateJSON(data []byte) error {
+	_, err := parseJSON(data)
+	return err
+}
+
+func (pr *Proof) UnmarshalJSON(data []byte) error {
+	fields, err := parseJSON(data)
+	if err != nil {
+		return err
+	}
+	curve, _ := curveByName(string(fields["curve"]))
+	hash, _ := hashByName(string(fields["hash"]))
+	var hash2 crypto.Hash
+	if name, ok := fields["hash2"]; ok {
+		hash2, _ = hashByName(string(name))
+	}
+	var opts Options
+	if string(fields["endian"]) == "little" {
+		opts.Endianness = LittleEndian
+	}
+	if string(fields["response"]) == "additive" {
+		opts.Convention = Additive
+	}
+	if bits, ok := fields["challengeBits"]; ok {
+		opts.ChallengeBits = int(binary.BigEndian.Uint16(bits))
+	}
+	opts.BindParameters = string(fields["bindParameters"]) == "true"
+	if name, ok := fields["transcriptOrder"]; ok {
+		opts.TranscriptOrder, _ = transcriptOrderByName(string(name))
+	}
+	var beacon *Beacon
+	if round, ok := fields["beaconRound"]; ok {
+		beacon = &Beacon{Round: binary.BigEndian.Uint64(round), Value: fields["beaconValue"]}
+	}
+
+	points := make([]*Point, len(jsonPointFields))
+	for i, name := range jsonPointFields {
+		points[i] = new(Point)
+		enc := Uncompressed
+		if len(fields[name]) == Compressed.encodedLen(curve) {
+			enc = Compressed
+		}
+		if err := points[i].UnmarshalEncoding(curve, enc, fields[name]); err != nil {
+			return &FieldError{Field: name, Err: err}
+		}
+	}
+	scalars := make([]*big.Int, len(jsonScalarFields))
+	for i, name := range jsonScalarFields {
+		scalars[i] = opts.Endianness.decode(fields[name])
+		if !scalarInRange(curve, scalars[i]) {
+			return &FieldError{Field: name, Err: ErrMalformedProof}
+		}
+	}
+	if !opts.challengeFits(scalars[0]) {
+		return &FieldError{Field: "C", Err: ErrMalformedProof}
+	}
+
+	pr.G, pr.H, pr.M, pr.Z = points[0], points[1], points[2], points[3]
+	pr.C, pr.R = scalars[0], scalars[1]
+	pr.params = params{hash: hash, hash2: hash2, opts: opts, beacon: beacon, salt: fields["salt"]}
+	return nil
+}
+
+// parseJSON does the work of ValidateJSON, returning the decoded contents of
+// each field. The curve and hash are returned as their names.
+func parseJSON(data []byte) (map[string][]byte, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("dleq: %w: %v", ErrMalformedProof, err)
+	}
+	str := func(name string) (string, error) {
+		value, ok := raw[name]
+		if !ok {
+			return "", &FieldError{Field: name, Err: ErrMissingField}
+		}
+		var s string
+		if err := json.Unmarshal(value, &s); err != nil {
+			return "", &FieldError{Field: name, Err: ErrFieldType}
+		}
+		return s, nil
+	}
+	fields := make(map[string][]byte)
+
+	name, err := str("curve")
+	if err != nil {
+		return nil, err
+	}
+	curve, ok := curveByName(name)
+	if !ok {
+		return nil, &FieldError{Field: "curve", Err: ErrUnknownCurve}
+	}
+	fields["curve"] = []byte(name)
+
+	name, err = str("hash")
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := hashByName(name); !ok {
+		return nil, &FieldError{Field: "hash", Err: ErrUnknownHash}
+	}
+	fields["hash"] = []byte(name)
+
+	if _, ok := raw["hash2"]; ok {
+		name, err = str("hash2")
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := hashByName(name); !ok {
+			return nil, &FieldError{Field: "hash2", Err: ErrUnknownHash}
+		}
+		fields["hash2"] = []byte(name)
+	}
+
+	if _, ok := raw["endian"]; ok {
+		name, err = str("endian")
+		if err != nil {
+			return nil, err
+		}
+		if name != "big" && name != "little" {
+			return nil, &FieldError{Field: "endian", Err: ErrUnknownEndianness}
+		}
+		fields["endian"] = []byte(name)
+	}
+	if _, ok := raw["response"]; ok {
+		name, err = str("response")
+		if err != nil {
+			return nil, err
+		}
+		if name != "subtractive" && name != "additive" {
+			return nil, &FieldError{Field: "response", Err: ErrUnknownConvention}
+		}
+		fields["response"] = []byte(name)
+	}
+	_, hasRound := raw["beaconRound"]
+	_, hasValue := raw["beaconValue"]
+	if hasRound || hasValue {
+		name, err = str("beaconRound")
+		if err != nil {
+			return nil, err
+		}
+		round, err := strconv.ParseUint(name, 10, 64)
+		if err != nil {
+			return nil, &FieldError{Field: "beaconRound", Err: ErrFieldType}
+		}
+		fields["beaconRound"] = binary.BigEndian.AppendUint64(nil, round)
+		name, err = str("beaconValue")
+		if err != nil {
+			return nil, err
+		}
+		if fields["beaconValue"], err = hex.DecodeString(name); err != nil {
+			return nil, &FieldError{Field: "beaconValue", Err: ErrFieldHex}
+		}
+	}
+
+	if _, ok := raw["challengeBits"]; ok {
+		name, err = str("challengeBits")
+		if err != nil {
+			return nil, err
+		}
+		bits, err := strconv.ParseUint(name, 10, 16)
+		if err != nil {
+			return nil, &FieldError{Field: "challengeBits", Err: ErrFieldType}
+		}
+		opts := Options{ChallengeBits: int(bits)}
+		if bits == 0 || opts.checkChallengeBits(curve) != nil {
+			return nil, &FieldError{Field: "challengeBits", Err: ErrChallengeBits}
+		}
+		fields["challengeBits"] = binary.BigEndian.AppendUint16(nil, uint16(bits))
+	}
+
+	if _, ok := raw["bindParameters"]; ok {
+		name, err = str("bindParameters")
+		if err != nil {
+			return nil, err
+		}
+		if name != "true" && name != "false" {
+			return nil, &FieldError{Field: "bindParameters", Err: ErrFieldType}
+		}
+		fields["bindParameters"] = []byte(name)
+	}
+
+	if _, ok := raw["transcriptOrder"]; ok {
+		name, err = str("transcriptOrder")
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := transcriptOrderByName(name); !ok {
+			return nil, &FieldError{Field: "transcriptOrder", Err: ErrTranscriptOrder}
+		}
+		fields["transcriptOrder"] = []byte(name)
+	}
+
+	if _, ok := raw["salt"]; ok {
+		name, err = str("salt")
+		if err != nil {
+			return nil, err
+		}
+		salt, err := hex.DecodeString(name)
+		if err != nil {
+			return nil, &FieldError{Field: "salt", Err: ErrFieldHex}
+		}
+		if len(salt) != SaltSize {
+			return nil, &FieldError{Field: "salt", Err: ErrFieldLength}
+		}
+		fields["salt"] = salt
+	}
+
+	decode := func(name string, lengths ...int) error {
+		s, err := str(name)
+		if err != nil {
+			return err
+		}
+		value, err := hex.DecodeString(s)
+		if err != nil {
+			return &FieldError{Field: name, Err: ErrFieldHex}
+		}
+		for _, length := range lengths {
+			if len(value) == length {
+				fields[name] = value
+				return nil
+			}
+		}
+		return &FieldError{Field: name, Err: ErrFieldLength}
+	}
+	for _, name := range jsonPointFields {
+		if err := decode(name, Uncompressed.encodedLen(curve), Compressed.encodedLen(curve)); err != nil {
+			return nil, err
+		}
+	}
+	scalarSize := ScalarLen(curve)
+	for _, name := range jsonScalarFields {
+		if err := decode(name, scalarSize); err != nil {
+			return nil, err
+		}
+	}
+	return fields, nil
+}
@@ -0,0 +1,105 @@
+package dleq
+
+import (
+	"crypto"
+	"crypto/elliptic"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func testJSONProof(t *testing.T) map[string]string {
+	G, H, M, Z, x := testStatement(t, elliptic.P256())
+	proof, err := NewProof(crypto.SHA256, G, H, M, Z, x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(proof)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fields map[string]string
+	if err := json.Unmarshal(data, &fields); err != nil {
+		t.Fatal(err)
+	}
+	return fields
+}
+
+func TestProofJSONRoundTrip(t *testing.T) {
+	data, err := json.Marshal(testJSONProof(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ValidateJSON(data); err != nil {
+		t.Fatal(err)
+	}
+	var proof Proof
+	if err := json.Unmarshal(data, &proof); err != nil {
+		t.Fatal(err)
+	}
+	if !proof.Verify() {
+		t.Fatal("decoded proof was invalid")
+	}
+}
+
+func TestValidateJSON(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(map[string]string)
+		field  string
+		err    error
+	}{
+		{"missing field", func(f map[string]string) { delete(f, "Z") }, "Z", ErrMissingField},
+		{"missing curve", func(f map[string]string) { delete(f, "curve") }, "curve", ErrMissingField},
+		{"short point", func(f map[string]string) { f["G"] = f["G"][:20] }, "G", ErrFieldLength},
+		{"long scalar", func(f map[string]string) { f["R"] += "00" }, "R", ErrFieldLength},
+		{"bad hex", func(f map[string]string) { f["C"] = "zz" + f["C"][2:] }, "C", ErrFieldHex},
+		{"unknown curve", func(f map[string]string) { f["curve"] = "P-192" }, "curve", ErrUnknownCurve},
+		{"unknown hash", func(f map[string]string) { f["hash"] = "SHA-1024" }, "hash", ErrUnknownHash},
+	}
+	for _, tt := range tests {
+		fields := testJSONProof(t)
+		tt.modify(fields)
+		data, err := json.Marshal(fields)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = ValidateJSON(data)
+		var fieldErr *FieldError
+		if !errors.As(err, &fieldErr) {
+			t.Errorf("%s: expected a FieldError, got %v", tt.name, err)
+			continue
+		}
+		if fieldErr.Field != tt.field || !errors.Is(err, tt.err) {
+			t.Errorf("%s: got %v", tt.name, err)
+		}
+		if err := json.Unmarshal(data, new(Proof)); err == nil {
+			t.Errorf("%s: unmarshal accepted an invalid proof", tt.name)
+		}
+	}
+}
+
+func TestProofJSONRejectsOffCurvePoint(t *testing.T) {
+	fields := testJSONProof(t)
+	// Right length, but flipping a bit of Y leaves the curve.
+	h := fields["H"]
+	last := "0"
+	if h[len(h)-1] == '0' {
+		last = "1"
+	}
+	fields["H"] = h[:len(h)-1] + last
+	data, err := json.Marshal(fields)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ValidateJSON(data); err != nil {
+		t.Fatalf("schema check should pass, got %v", err)
+	}
+	err = json.Unmarshal(data, new(Proof))
+	var fieldErr *FieldError
+	if !errors.As(err, &fieldErr) || fieldErr.Field != "H" || !errors.Is(err, ErrInvalidPoint) {
+		t.Fatalf("expected a FieldError for H, got %v", err)
+	}
+}
@@ -0,0 +1,55 @@
+package dleq
+
+import (
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+)
+
+// StatementFromPublicKey builds the statement that the standard library
+// public key pub and z = m^x share a secret key: G is the base point of the
+// key's curve and H the key's point. pub may be an *ecdsa.PublicKey or an
+// *ecdh.PublicKey on one of the NIST curves; m and z must be on the same
+// curve.
+func StatementFromPublicKey(pub crypto.PublicKey, m, z *Point) (*Statement, error) {
+	var h *Point
+	switch pub := pub.(type) {
+	case *ecdsa.PublicKey:
+		if pub.Curve == nil || pub.X == nil || pub.Y == nil {
+			return nil, ErrUnsupportedKey
+		}
+		h = &Point{Curve: pub.Curve, X: pub.X, Y: pub.Y}
+	case *ecdh.PublicKey:
+		curve, ok := ecdhCurve(pub.Curve())
+		if !ok {
+			return nil, ErrUnsupportedKey
+		}
+		h = new(Point)
+		if err := h.Unmarshal(curve, pub.Bytes()); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, ErrUnsupportedKey
+	}
+
+	stmt := &Statement{G: basePoint(h.Curve), H: h, M: m, Z: z}
+	if err := stmt.check(); err != nil {
+		return nil, err
+	}
+	return stmt, nil
+}
+
+// ecdhCurve maps an ecdh curve to the crypto/elliptic curve with the same
+// point encoding. X25519 has none.
+func ecdhCurve(curve ecdh.Curve) (elliptic.Curve, bool) {
+	switch curve {
+	case ecdh.P256():
+		return elliptic.P256(), true
+	case ecdh.P384():
+		return elliptic.P384(), true
+	case ecdh.P521():
+		return elliptic.P521(), true
+	}
+	return nil, false
+}
@@ -0,0 +1,75 @@
+package dleq
+
+import (
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"errors"
+	"testing"
+)
+
+func TestStatementFromECDSAKey(t *testing.T) {
+	curve := elliptic.P256()
+	key, err := ecdsa.GenerateKey(curve, rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, M, _, _, _ := testStatement(t, curve)
+	Z, err := PublicKey(M, key.D)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stmt, err := StatementFromPublicKey(&key.PublicKey, M, Z)
+	if err != nil {
+		t.Fatal(err)
+	}
+	proof, err := NewProver(crypto.SHA256, key.D).Prove(stmt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !proof.Verify() {
+		t.Fatal("proof was invalid")
+	}
+
+	// The same statement from the key's ecdh form.
+	ecdhKey, err := key.PublicKey.ECDH()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fromECDH, err := StatementFromPublicKey(ecdhKey, M, Z)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !fromECDH.H.equal(stmt.H) || !fromECDH.G.equal(stmt.G) {
+		t.Fatal("ecdh and ecdsa keys gave different statements")
+	}
+}
+
+func TestStatementFromPublicKeyErrors(t *testing.T) {
+	_, M, _, Z, _ := testStatement(t, elliptic.P256())
+	edKey, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := StatementFromPublicKey(edKey, M, Z); err != ErrUnsupportedKey {
+		t.Errorf("ed25519: expected ErrUnsupportedKey, got %v", err)
+	}
+	xKey, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := StatementFromPublicKey(xKey.PublicKey(), M, Z); err != ErrUnsupportedKey {
+		t.Errorf("X25519: expected ErrUnsupportedKey, got %v", err)
+	}
+	p384, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := StatementFromPublicKey(&p384.PublicKey, M, Z); !errors.Is(err, ErrInconsistentCurves) {
+		t.Errorf("mixed curves: expected ErrInconsistentCurves, got %v", err)
+	}
+}
@@ -0,0 +1,454 @@
+package dleq
+
+import (
+	"crypto"
+	"encoding/binary"
+)
+
+// The binary proof format is self-describing, so a reader needs no
+// out-of-band agreement on parameters:
+//
+//	version  1 byte, currently binaryVersion
+//	curve    1 byte, index into the curve registry
+//	hash     1 byte, the crypto.Hash value
+//	flags    1 byte, see below
+//	ext      1 byte of extension flags, only with flagExtended
+//	bits     2-byte big-endian challenge bit length, only with flagTruncated
+//	base     1 byte, only with flagBasePoints, see below
+//	order    1 byte, the TranscriptOrder, only with extOrder
+//	G, H, M, Z  each a 1-byte PointEncoding tag followed by the point
+//	C        a scalar the size of the group order, or of bits if truncated
+//	R        a scalar the size of the group order
+//
+// followed by optional fields in the order of the flags announcing them:
+//
+//	beacon   8-byte big-endian round, uvarint length, value
+//	hash2    1 byte, the second crypto.Hash of a hybrid proof
+//	salt     SaltSize bytes, only with extSalt
+//
+// Points equal to the curve's standard base point are left out: bit i of
+// base, counting G, H, M, Z from the low bit, is set for each of them, and
+// the reader fills them back in from the curve parameters. The writer sets
+// flagBasePoints whenever there is one.
+//
+// The flags byte ran out, so later options are flagged in the extension
+// byte instead. The writer includes it only when one of its flags is set,
+// and a reader rejects an extension byte that is zero.
+//
+// Flag bits not defined here must be zero.
+const (
+	binaryVersion    = 1
+	binaryHeaderSize = 4
+
+	flagLittleEndian = 1 << 0 // C and R are little-endian
+	flagAdditive     = 1 << 1 // R uses the additive convention
+	flagBeacon       = 1 << 2 // a beacon follows the scalars
+	flagHybrid       = 1 << 3 // a second hash id follows
+	flagTruncated    = 1 << 4 // the challenge is truncated
+	flagBasePoints   = 1 << 5 // some points are left out as the base point
+	flagBoundParams  = 1 << 6 // the transcript binds the curve and hash
+	flagExtended     = 1 << 7 // an extension flags byte follows
+	knownFlags       = flagLittleEndian | flagAdditive | flagBeacon | flagHybrid | flagTruncated | flagBasePoints | flagBoundParams | flagExtended
+
+	extOrder      = 1 << 0 // the transcript points are reordered
+	extSalt       = 1 << 1 // a salt follows the other optional fields
+	knownExtFlags = extOrder | extSalt
+)
+
+// Decoder limits. DefaultMaxProofSize fits a P-521 proof with every optional
+// field, uncompressed points and a beacon value of DefaultMaxFieldSize, which
+// is the largest proof the format can carry under the default field limit.
+const (
+	DefaultMaxFieldSize = 256
+	DefaultMaxProofSize = binaryHeaderSize + 1 + 2 + 1 + 1 + 4*(1+1+2*66) + 2*66 +
+		8 + binary.MaxVarintLen64 + DefaultMaxFieldSize + 1 + SaltSize
+)
+
+// DecoderOptions bound what a decoder accepts, so that untrusted input can't
+// make it allocate more than a legitimate proof would need. Zero values
+// select the defaults.
+type DecoderOptions struct {
+	MaxProofSize int // total encoded bytes
+	MaxFieldSize int // any length-prefixed field, such as a beacon value
+}
+
+func (opts *DecoderOptions) maxProofSize() int {
+	if opts == nil || opts.MaxProofSize == 0 {
+		return DefaultMaxProofSize
+	}
+	return opts.MaxProofSize
+}
+
+func (opts *DecoderOptions) maxFieldSize() int {
+	if opts == nil || opts.MaxFieldSize == 0 {
+		return DefaultMaxFieldSize
+	}
+	return opts.MaxFieldSize
+}
+
+// MarshalBinary encodes the proof with every point uncompressed.
+func (pr *Proof) MarshalBinary() ([]byte, error) {
+	return pr.MarshalBinaryEncoding(Uncompressed, Uncompressed)
+}
+
+// MarshalBinaryEncoding encodes the proof with the generators (G, M) and the
+// public keys (H, Z) in the given point encodings. The encoding of each point
+// is recorded, so the two may differ.
+func (pr *Proof) MarshalBinaryEncoding(generators, keys PointEncoding) ([]byte, error) {
+	return pr.appendBinary(nil, generators, keys)
+}
+
+// AppendTo appends the proof as MarshalBinary encodes it to dst and returns
+// the extended slice, or dst unchanged and an error. Proofs appended one
+// after another can be read back with ReadFrom or VerifyStream, since the
+// format determines its own length.
+func (pr *Proof) AppendTo(dst []byte) ([]byte, error) {
+	return pr.appendBinary(dst, Uncompressed, Uncompressed)
+}
+
+// MarshalTo writes the proof as MarshalBinary encodes it to the start of
+// buf and returns the number of bytes written, which is Size. If buf is
+// shorter than that it returns ErrBufferTooSmall, and on any error nothing
+// is written to buf.
+func (pr *Proof) MarshalTo(buf []byte) (int, error) {
+	size := pr.Size()
+	if size == 0 {
+		// Size can't encode it either; let the encoder say why.
+		_, err := pr.appendBinary(nil, Uncompressed, Uncompressed)
+		return 0, err
+	}
+	if len(buf) < size {
+		return 0, ErrBufferTooSmall
+	}
+	out, err := pr.appendBinary(buf[:0], Uncompressed, Uncompressed)
+	return len(out), err
+}
+
+// Size is the length of the proof's MarshalBinary encoding, or 0 if it
+// can't be encoded.
+func (pr *Proof) Size() int {
+	if !pr.IsComplete() || !pr.IsSane() {
+		return 0
+	}
+	curve := pr.G.Curve
+	if _, ok := curveID(curve); !ok {
+		return 0
+	}
+	size := binaryHeaderSize
+	ext := pr.extFlags()
+	if ext != 0 {
+		size++
+	}
+	if pr.opts.ChallengeBits != 0 {
+		size += 2
+	}
+	if ext&extOrder != 0 {
+		size++
+	}
+	base := basePoint(curve)
+	hasBase := false
+	for _, p := range []*Point{pr.G, pr.H, pr.M, pr.Z} {
+		if p.equal(base) {
+			hasBase = true
+			continue
+		}
+		size += 1 + p.encodedLen(Uncompressed)
+	}
+	if hasBase {
+		size++
+	}
+	size += pr.opts.challengeSize(curve) + ScalarLen(curve)
+	if pr.beacon != nil {
+		var varint [binary.MaxVarintLen64]byte
+		size += 8 + binary.PutUvarint(varint[:], uint64(len(pr.beacon.Value))) + len(pr.beacon.Value)
+	}
+	if pr.hash2 != 0 {
+		size++
+	}
+	if ext&extSalt != 0 {
+		size += SaltSize
+	}
+	return size
+}
+
+// extFlags returns the extension flags the proof is written with.
+func (pr *Proof) extFlags() byte {
+	var ext byte
+	if pr.opts.TranscriptOrder != TranscriptGHMZ {
+		ext |= extOrder
+	}
+	if pr.salt != nil {
+		ext |= extSalt
+	}
+	return ext
+}
+
+func (pr *Proof) appendBinary(dst []byte, generators, keys PointEncoding) ([]byte, error) {
+	if !pr.IsComplete() {
+		return dst, ErrIncompleteProof
+	}
+	if !pr.IsSane() {
+		return dst, ErrInconsistentCurves
+	}
+	curve := pr.G.Curve
+	id, ok := curveID(curve)
+	if !ok {
+		return dst, ErrUnknownCurve
+	}
+	if !scalarInRange(curve, pr.C) || !scalarInRange(curve, pr.R) {
+		return dst, ErrMalformedProof
+	}
+	if pr.opts.checkChallengeBits(curve) != nil || !pr.opts.challengeFits(pr.C) {
+		return dst, ErrMalformedProof
+	}
+	if err := pr.opts.checkOrder(); err != nil {
+		return dst, err
+	}
+	if pr.salt != nil && len(pr.salt) != SaltSize {
+		return dst, ErrMalformedProof
+	}
+	if !validHash(pr.hash) || (pr.hash2 != 0 && !validHash(pr.hash2)) {
+		return dst, ErrUnknownHash
+	}
+
+	var flags byte
+	if pr.opts.Endianness == LittleEndian {
+		flags |= flagLittleEndian
+	}
+	if pr.opts.Convention == Additive {
+		flags |= flagAdditive
+	}
+	if pr.beacon != nil {
+		// Don't write what a default decoder would refuse.
+		if len(pr.beacon.Value) > DefaultMaxFieldSize {
+			return dst, ErrProofTooLarge
+		}
+		flags |= flagBeacon
+	}
+	if pr.hash2 != 0 {
+		flags |= flagHybrid
+	}
+	if pr.opts.ChallengeBits != 0 {
+		flags |= flagTruncated
+	}
+	if pr.opts.BindParameters {
+		flags |= flagBoundParams
+	}
+	ext := pr.extFlags()
+	if ext != 0 {
+		flags |= flagExtended
+	}
+	points := []*Point{pr.G, pr.H, pr.M, pr.Z}
+	base := basePoint(curve)
+	var baseMask byte
+	for i, p := range points {
+		if p.equal(base) {
+			baseMask |= 1 << i
+		}
+	}
+	if baseMask != 0 {
+		flags |= flagBasePoints
+	}
+
+	out := append(dst, binaryVersion, id, byte(pr.hash), flags)
+	if ext != 0 {
+		out = append(out, ext)
+	}
+	if pr.opts.ChallengeBits != 0 {
+		out = binary.BigEndian.AppendUint16(out, uint16(pr.opts.ChallengeBits))
+	}
+	if baseMask != 0 {
+		out = append(out, baseMask)
+	}
+	if ext&extOrder != 0 {
+		out = append(out, byte(pr.opts.TranscriptOrder))
+	}
+	encodings := []PointEncoding{generators, keys, generators, keys}
+	for i, p := range points {
+		if baseMask&(1<<i) != 0 {
+			continue
+		}
+		data, err := p.MarshalEncoding(encodings[i])
+		if err != nil {
+			return dst, err
+		}
+		out = append(out, byte(encodings[i]))
+		out = append(out, data...)
+	}
+	out = append(out, pr.opts.Endianness.encodeSize(pr.C, pr.opts.challengeSize(curve))...)
+	out = append(out, pr.opts.Endianness.encode(curve, pr.R)...)
+	if pr.beacon != nil {
+		out = binary.BigEndian.AppendUint64(out, pr.beacon.Round)
+		out = binary.AppendUvarint(out, uint64(len(pr.beacon.Value)))
+		out = append(out, pr.beacon.Value...)
+	}
+	if pr.hash2 != 0 {
+		out = append(out, byte(pr.hash2))
+	}
+	if ext&extSalt != 0 {
+		out = append(out, pr.salt...)
+	}
+	return out, nil
+}
+
+// validHash reports whether h fits the one-byte identifier and is linked in.
+func validHash(h crypto.Hash) bool {
+	return h != 0 && h <= 0xff && h.Available()
+}
+
+// UnmarshalBinary decodes a proof within the default DecoderOptions.
+func (pr *Proof) UnmarshalBinary(data []byte) error {
+	return pr.UnmarshalBinaryOptions(data, nil)
+}
+
+// UnmarshalBinaryOptions decodes a proof, returning ErrProofTooLarge for
+// input beyond limits. A nil limits selects the defaults.
+func (pr *Proof) UnmarshalBinaryOptions(data []byte, limits *DecoderOptions) error {
+	if len(data) > limits.maxProofSize() {
+		return ErrProofTooLarge
+	}
+	if len(data) < binaryHeaderSize {
+		return ErrMalformedProof
+	}
+	flags := data[3]
+	if data[0] != binaryVersion || flags&^knownFlags != 0 {
+		return ErrMalformedProof
+	}
+	var opts Options
+	if flags&flagLittleEndian != 0 {
+		opts.Endianness = LittleEndian
+	}
+	if flags&flagAdditive != 0 {
+		opts.Convention = Additive
+	}
+	opts.BindParameters = flags&flagBoundParams != 0
+	curve, ok := curveByID(data[1])
+	if !ok {
+		return ErrUnknownCurve
+	}
+	hash := crypto.Hash(data[2])
+	if !validHash(hash) {
+		return ErrUnknownHash
+	}
+	data = data[binaryHeaderSize:]
+	var ext byte
+	if flags&flagExtended != 0 {
+		if len(data) < 1 || data[0] == 0 || data[0]&^knownExtFlags != 0 {
+			return ErrMalformedProof
+		}
+		ext = data[0]
+		data = data[1:]
+	}
+	if flags&flagTruncated != 0 {
+		if len(data) < 2 {
+			return ErrMalformedProof
+		}
+		opts.ChallengeBits = int(binary.BigEndian.Uint16(data))
+		if opts.ChallengeBits == 0 || opts.checkChallengeBits(curve) != nil {
+			return ErrMalformedProof
+		}
+		data = data[2:]
+	}
+	var baseMask byte
+	if flags&flagBasePoints != 0 {
+		if len(data) < 1 || data[0] == 0 || data[0]&^0xf != 0 {
+			return ErrMalformedProof
+		}
+		baseMask = data[0]
+		data = data[1:]
+	}
+	if ext&extOrder != 0 {
+		if len(data) < 1 {
+			return ErrMalformedProof
+		}
+		opts.TranscriptOrder = TranscriptOrder(data[0])
+		if opts.TranscriptOrder == TranscriptGHMZ || opts.checkOrder() != nil {
+			return ErrMalformedProof
+		}
+		data = data[1:]
+	}
+
+	points := make([]*Point, 4)
+	for i := range points {
+		if baseMask&(1<<i) != 0 {
+			points[i] = basePoint(curve)
+			continue
+		}
+		if len(data) < 2 {
+			return ErrMalformedProof
+		}
+		enc := PointEncoding(data[0])
+		size := enc.prefixLen(curve, data[1])
+		if size == 0 {
+			return ErrUnknownEncoding
+		}
+		if len(data) < 1+size {
+			return ErrMalformedProof
+		}
+		points[i] = new(Point)
+		if err := points[i].UnmarshalEncoding(curve, enc, data[1:1+size]); err != nil {
+			return err
+		}
+		data = data[1+size:]
+	}
+
+	scalarSize := ScalarLen(curve)
+	challengeSize := opts.challengeSize(curve)
+	if len(data) < challengeSize+scalarSize {
+		return ErrMalformedProof
+	}
+	c := opts.Endianness.decode(data[:challengeSize])
+	r := opts.Endianness.decode(data[challengeSize : challengeSize+scalarSize])
+	if !opts.challengeFits(c) {
+		return ErrMalformedProof
+	}
+	data = data[challengeSize+scalarSize:]
+
+	var beacon *Beacon
+	if flags&flagBeacon != 0 {
+		if len(data) < 8 {
+			return ErrMalformedProof
+		}
+		round := binary.BigEndian.Uint64(data)
+		length, n := binary.Uvarint(data[8:])
+		if n <= 0 {
+			return ErrMalformedProof
+		}
+		if length > uint64(limits.maxFieldSize()) {
+			return ErrProofTooLarge
+		}
+		if length > uint64(len(data)-8-n) {
+			return ErrMalformedProof
+		}
+		end := 8 + n + int(length)
+		beacon = &Beacon{Round: round, Value: append([]byte{}, data[8+n:end]...)}
+		data = data[end:]
+	}
+	var hash2 crypto.Hash
+	if flags&flagHybrid != 0 {
+		if len(data) < 1 {
+			return ErrMalformedProof
+		}
+		hash2 = crypto.Hash(data[0])
+		if !validHash(hash2) {
+			return ErrUnknownHash
+		}
+		data = data[1:]
+	}
+	var salt []byte
+	if ext&extSalt != 0 {
+		if len(data) < SaltSize {
+			return ErrMalformedProof
+		}
+		salt = append([]byte{}, data[:SaltSize]...)
+		data = data[SaltSize:]
+	}
+	if len(data) != 0 {
+		return ErrMalformedProof
+	}
+
+	pr.G, pr.H, pr.M, pr.Z = points[0], points[1], points[2], points[3]
+	pr.C, pr.R = c, r
+	pr.params = params{hash: hash, hash2: hash2, opts: opts, beacon: beacon, salt: salt}
+	return nil
+}
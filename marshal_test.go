@@ -0,0 +1,403 @@
+package dleq
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/elliptic"
+	"encoding/binary"
+	"math/big"
+	"testing"
+)
+
+func TestPointEncodingRoundTrip(t *testing.T) {
+	curve := elliptic.P256()
+	G, _, _, _, _ := testStatement(t, curve)
+
+	for _, enc := range []PointEncoding{Uncompressed, Compressed, Hybrid} {
+		data, err := G.MarshalEncoding(enc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(data) != enc.encodedLen(curve) {
+			t.Errorf("encoding %d: got %d bytes, expected %d", enc, len(data), enc.encodedLen(curve))
+		}
+		var p Point
+		if err := p.UnmarshalEncoding(curve, enc, data); err != nil {
+			t.Fatalf("encoding %d: %v", enc, err)
+		}
+		if p.X.Cmp(G.X) != 0 || p.Y.Cmp(G.Y) != 0 {
+			t.Errorf("encoding %d: decoded a different point", enc)
+		}
+	}
+}
+
+func TestHybridRejectsWrongParity(t *testing.T) {
+	G, _, _, _, _ := testStatement(t, elliptic.P256())
+	data, err := G.MarshalEncoding(Hybrid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data[0] ^= 1
+	var p Point
+	if err := p.UnmarshalEncoding(G.Curve, Hybrid, data); err != ErrInvalidPoint {
+		t.Fatalf("expected ErrInvalidPoint, got %v", err)
+	}
+}
+
+func TestProofBinaryRoundTrip(t *testing.T) {
+	G, H, M, Z, x := testStatement(t, elliptic.P256())
+	proof, err := NewProof(crypto.SHA256, G, H, M, Z, x)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encodings := []PointEncoding{Uncompressed, Compressed, Hybrid}
+	for _, generators := range encodings {
+		for _, keys := range encodings {
+			data, err := proof.MarshalBinaryEncoding(generators, keys)
+			if err != nil {
+				t.Fatal(err)
+			}
+			decoded := new(Proof)
+			if err := decoded.UnmarshalBinary(data); err != nil {
+				t.Fatalf("encodings (%d, %d): %v", generators, keys, err)
+			}
+			if !decoded.Verify() {
+				t.Fatalf("encodings (%d, %d): decoded proof was invalid", generators, keys)
+			}
+		}
+	}
+}
+
+func TestProofBinaryRejectsTruncation(t *testing.T) {
+	G, H, M, Z, x := testStatement(t, elliptic.P256())
+	proof, err := NewProof(crypto.SHA256, G, H, M, Z, x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := proof.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := new(Proof).UnmarshalBinary(data[:len(data)-1]); err != ErrMalformedProof {
+		t.Fatalf("expected ErrMalformedProof, got %v", err)
+	}
+}
+
+func TestProofBinarySize(t *testing.T) {
+	// For P-256: a 4-byte header, four tagged 65-byte points and two 32-byte
+	// scalars, or 33-byte points when compressed. A change here is a change
+	// to the wire format.
+	const (
+		uncompressedSize = 4 + 4*(1+65) + 2*32
+		compressedSize   = 4 + 4*(1+33) + 2*32
+	)
+	G, H, M, Z, x := testStatement(t, elliptic.P256())
+	proof, err := NewProof(crypto.SHA256, G, H, M, Z, x)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := proof.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) != uncompressedSize {
+		t.Errorf("uncompressed proof is %d bytes, expected %d", len(data), uncompressedSize)
+	}
+	data, err = proof.MarshalBinaryEncoding(Compressed, Compressed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) != compressedSize {
+		t.Errorf("compressed proof is %d bytes, expected %d", len(data), compressedSize)
+	}
+}
+
+func TestProofBinaryRejectsOversizedField(t *testing.T) {
+	curve := elliptic.P256()
+	G, H, M, Z, x := testStatement(t, curve)
+	proof, err := NewProofWithBeacon(crypto.SHA256, &Statement{G: G, H: H, M: M, Z: Z}, x, 1000, []byte("randomness"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := proof.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Swap the beacon length for one claiming a terabyte, keeping the rest.
+	offset := binaryHeaderSize + 4*(1+Uncompressed.encodedLen(curve)) + 2*32 + 8
+	_, n := binary.Uvarint(data[offset:])
+	crafted := append([]byte{}, data[:offset]...)
+	crafted = binary.AppendUvarint(crafted, 1<<40)
+	crafted = append(crafted, data[offset+n:]...)
+
+	var decoded Proof
+	allocs := testing.AllocsPerRun(10, func() {
+		if err := decoded.UnmarshalBinary(crafted); err != ErrProofTooLarge {
+			t.Fatalf("expected ErrProofTooLarge, got %v", err)
+		}
+	})
+	// The points are decoded before the beacon is reached, but nothing may
+	// be sized by the claimed length.
+	if limit := testing.AllocsPerRun(10, func() { decoded.UnmarshalBinary(data) }); allocs >= limit {
+		t.Fatalf("rejection made %.0f allocations, a full decode makes %.0f", allocs, limit)
+	}
+
+	// A tighter limit rejects the real value too.
+	if err := decoded.UnmarshalBinaryOptions(data, &DecoderOptions{MaxFieldSize: 4}); err != ErrProofTooLarge {
+		t.Fatalf("expected ErrProofTooLarge, got %v", err)
+	}
+}
+
+func TestProofBinaryRejectsOversizedInput(t *testing.T) {
+	data := make([]byte, DefaultMaxProofSize+1)
+	data[0] = binaryVersion
+	allocs := testing.AllocsPerRun(10, func() {
+		if err := new(Proof).UnmarshalBinary(data); err != ErrProofTooLarge {
+			t.Fatalf("expected ErrProofTooLarge, got %v", err)
+		}
+	})
+	if allocs > 0 {
+		t.Fatalf("rejection made %.0f allocations", allocs)
+	}
+	if err := new(Proof).UnmarshalBinaryOptions(data[:100], &DecoderOptions{MaxProofSize: 99}); err != ErrProofTooLarge {
+		t.Fatalf("expected ErrProofTooLarge, got %v", err)
+	}
+}
+
+func TestDefaultMaxProofSize(t *testing.T) {
+	// The largest proof the default limits admit must still decode.
+	G, H, M, Z, x := testStatement(t, elliptic.P521())
+	value := bytes.Repeat([]byte{0xff}, DefaultMaxFieldSize)
+	proof, err := NewProofWithBeacon(crypto.SHA512, &Statement{G: G, H: H, M: M, Z: Z}, x, 1000, value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	proof.hash2 = crypto.SHA256
+	proof.opts.TranscriptOrder = TranscriptGMHZ
+	proof.salt = bytes.Repeat([]byte{0xee}, SaltSize)
+	data, err := proof.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) > DefaultMaxProofSize {
+		t.Fatalf("largest proof is %d bytes, limit is %d", len(data), DefaultMaxProofSize)
+	}
+	if err := new(Proof).UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+
+	proof.beacon.Value = append(value, 0)
+	if _, err := proof.MarshalBinary(); err != ErrProofTooLarge {
+		t.Fatalf("expected ErrProofTooLarge, got %v", err)
+	}
+}
+
+func TestProofBinaryBasePoint(t *testing.T) {
+	curve := elliptic.P256()
+	_, _, M, _, x := testStatement(t, curve)
+	G := basePoint(curve)
+	H, err := PublicKey(G, x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	Z, err := PublicKey(M, x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	proof, err := NewProof(crypto.SHA256, G, H, M, Z, x)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// G is replaced by one bit in an extra byte.
+	const size = 4 + 1 + 3*(1+65) + 2*32
+	data, err := proof.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) != size {
+		t.Fatalf("proof is %d bytes, expected %d", len(data), size)
+	}
+	if data[3]&flagBasePoints == 0 || data[4] != 1 {
+		t.Fatalf("flags %#x, base point mask %#x", data[3], data[4])
+	}
+	decoded := new(Proof)
+	if err := decoded.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if !decoded.G.equal(G) || !decoded.Verify() {
+		t.Fatal("base point was not reconstructed")
+	}
+
+	for _, mask := range []byte{0, 0x10} {
+		bad := append([]byte{}, data...)
+		bad[4] = mask
+		if err := new(Proof).UnmarshalBinary(bad); err != ErrMalformedProof {
+			t.Errorf("mask %#x: expected ErrMalformedProof, got %v", mask, err)
+		}
+	}
+}
+
+func TestProofMarshalTo(t *testing.T) {
+	curve := elliptic.P384()
+	G, H, M, Z, x := testStatement(t, curve)
+	stmt := &Statement{G: G, H: H, M: M, Z: Z}
+	plain, err := NewProof(crypto.SHA256, G, H, M, Z, x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	options, err := NewProofWithOptions(crypto.SHA256, G, H, M, Z, x, &Options{ChallengeBits: 128, TranscriptOrder: TranscriptMZ})
+	if err != nil {
+		t.Fatal(err)
+	}
+	beacon, err := NewProofWithBeacon(crypto.SHA384, stmt, x, 1<<40, bytes.Repeat([]byte{1}, 200))
+	if err != nil {
+		t.Fatal(err)
+	}
+	beacon.hash2 = crypto.SHA256
+	base := basePoint(curve)
+	onBase, err := NewProof(crypto.SHA256, base, mustPublicKey(t, base, x), M, Z, x)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, pr := range []*Proof{plain, options, beacon, onBase} {
+		want, err := pr.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if pr.Size() != len(want) {
+			t.Fatalf("proof %d: Size is %d, encoding is %d bytes", i, pr.Size(), len(want))
+		}
+
+		buf := make([]byte, len(want)+3)
+		n, err := pr.MarshalTo(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n != len(want) || !bytes.Equal(buf[:n], want) {
+			t.Fatalf("proof %d: MarshalTo differs from MarshalBinary", i)
+		}
+
+		// An undersized buffer is left exactly as it was.
+		short := bytes.Repeat([]byte{0xaa}, len(want)-1)
+		if n, err := pr.MarshalTo(short); n != 0 || err != ErrBufferTooSmall {
+			t.Fatalf("proof %d: expected ErrBufferTooSmall, got %d, %v", i, n, err)
+		}
+		if !bytes.Equal(short, bytes.Repeat([]byte{0xaa}, len(want)-1)) {
+			t.Fatalf("proof %d: undersized buffer was written to", i)
+		}
+	}
+
+	if n, err := new(Proof).MarshalTo(make([]byte, 1024)); n != 0 || err != ErrIncompleteProof {
+		t.Fatalf("expected ErrIncompleteProof, got %d, %v", n, err)
+	}
+}
+
+func TestIdentityEncoding(t *testing.T) {
+	curve := elliptic.P256()
+	identity := &Point{Curve: curve, X: new(big.Int), Y: new(big.Int)}
+
+	for _, enc := range []PointEncoding{Uncompressed, Compressed, Hybrid} {
+		data, err := identity.MarshalEncoding(enc)
+		if err != nil {
+			t.Fatalf("encoding %d: %v", enc, err)
+		}
+		if !bytes.Equal(data, []byte{0x00}) {
+			t.Errorf("encoding %d: identity encoded as %x", enc, data)
+		}
+		if len(data) != identity.encodedLen(enc) {
+			t.Errorf("encoding %d: encodedLen is %d, encoded %d bytes", enc, identity.encodedLen(enc), len(data))
+		}
+		var p Point
+		if err := p.UnmarshalEncoding(curve, enc, data); err != nil {
+			t.Fatalf("encoding %d: %v", enc, err)
+		}
+		if !p.IsIdentity() {
+			t.Errorf("encoding %d: decoded a different point", enc)
+		}
+	}
+	if _, err := identity.MarshalEncoding(PointEncoding(5)); err != ErrUnknownEncoding {
+		t.Errorf("unknown encoding: got %v, expected ErrUnknownEncoding", err)
+	}
+}
+
+func TestProofBinaryIdentityPoint(t *testing.T) {
+	G, H, M, Z, x := testStatement(t, elliptic.P256())
+	proof, err := NewProof(crypto.SHA256, G, H, M, Z, x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := proof.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Replace H with the one-byte identity. The decoder has to find the
+	// end of H from its own prefix to land on M, Z and the scalars.
+	start := binaryHeaderSize + (1 + 65) + 1
+	spliced := append(append(append([]byte{}, data[:start]...), 0x00), data[start+65:]...)
+	var decoded Proof
+	if err := decoded.UnmarshalBinary(spliced); err != nil {
+		t.Fatal(err)
+	}
+	if !decoded.H.IsIdentity() || !decoded.M.equal(M) || !decoded.Z.equal(Z) {
+		t.Fatal("points decoded out of place")
+	}
+	if decoded.C.Cmp(proof.C) != 0 || decoded.R.Cmp(proof.R) != 0 {
+		t.Fatal("scalars decoded out of place")
+	}
+	if decoded.Verify() {
+		t.Error("verified a proof with the identity as H")
+	}
+
+	// The stream reader agrees on where the proof ends.
+	stream := bytes.NewReader(append(spliced, data...))
+	var streamed Proof
+	n, err := streamed.ReadFrom(stream)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != int64(len(spliced)) {
+		t.Errorf("read %d bytes, expected %d", n, len(spliced))
+	}
+}
+
+func TestUnmarshalDetectsEncoding(t *testing.T) {
+	curve := elliptic.P256()
+	G, _, _, _, _ := testStatement(t, curve)
+
+	for _, enc := range []PointEncoding{Uncompressed, Compressed, Hybrid} {
+		data, err := G.MarshalEncoding(enc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var p Point
+		if err := p.Unmarshal(curve, data); err != nil {
+			t.Fatalf("encoding %d: %v", enc, err)
+		}
+		if !p.equal(G) {
+			t.Errorf("encoding %d: decoded a different point", enc)
+		}
+	}
+
+	compressed, _ := G.MarshalEncoding(Compressed)
+	for _, tc := range []struct {
+		name string
+		data []byte
+		err  error
+	}{
+		{"empty", nil, ErrInvalidPoint},
+		{"identity", []byte{0x00}, ErrIdentityPoint},
+		{"unknown prefix", append([]byte{0x05}, compressed[1:]...), ErrUnknownEncoding},
+		{"truncated", compressed[:len(compressed)-1], ErrInvalidPoint},
+	} {
+		var p Point
+		if err := p.Unmarshal(curve, tc.data); err != tc.err {
+			t.Errorf("%s: got %v, expected %v", tc.name, err, tc.err)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package dleq
+
+import (
+	"crypto"
+	crand "crypto/rand"
+	"math/big"
+)
+
+// A MinimalProof transmits the commitments (a, b) in place of the challenge.
+// Since c is fully determined by the transcript, the verifier recomputes
+// c = H(g, h, m, z, a, b) itself and then checks the group equations
+// a = rG + cH and b = rM + cZ directly.
+//
+// The tradeoff: a and b are two group elements where C was a single scalar,
+// so on the wire a MinimalProof is larger than the (C, R) form of a Proof.
+// What it buys is that validity is a pair of group equations rather than a
+// hash comparison, which is the shape needed to check many proofs at once
+// with a random linear combination, and the commitments are directly
+// available for protocols that need them.
+type MinimalProof struct {
+	G, M *Point   // generators known by both parties
+	H, Z *Point   // "public keys" we want to compare
+	A, B *Point   // commitments (g^s, m^s)
+	R    *big.Int // response value
+
+	params
+}
+
+// NewMinimalProof is NewProof, returning the commitment form of the proof.
+func NewMinimalProof(hash crypto.Hash, g, h, m, z *Point, x *big.Int) (*MinimalProof, error) {
+	proof := newProof(hash, &Statement{G: g, H: h, M: m, Z: z}, nil)
+	a, b, err := proof.prove(x, crand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	return &MinimalProof{
+		G: proof.G, M: proof.M,
+		H: proof.H, Z: proof.Z,
+		A: a, B: b,
+		R:      proof.R,
+		params: proof.params,
+	}, nil
+}
+
+// Minimal converts pr to its commitment form by recomputing (a, b). It does
+// not check that pr is valid.
+func (pr *Proof) Minimal() *MinimalProof {
+	a, b := pr.commitments()
+	return &MinimalProof{
+		G: pr.G, M: pr.M,
+		H: pr.H, Z: pr.Z,
+		A: a, B: b,
+		R:      pr.R,
+		params: pr.params,
+	}
+}
+
+func (mp *MinimalProof) IsComplete() bool {
+	return mp.G != nil && mp.M != nil && mp.H != nil && mp.Z != nil &&
+		mp.A != nil && mp.B != nil && mp.R != nil
+}
+
+func (mp *MinimalProof) IsSane() bool {
+	curve := mp.G.Curve
+	for _, p := range []*Point{mp.H, mp.M, mp.Z, mp.A, mp.B} {
+		if !SameCurve(p.Curve, curve) {
+			return false
+		}
+	}
+	for _, p := range []*Point{mp.G, mp.H, mp.M, mp.Z, mp.A, mp.B} {
+		if !p.IsOnCurve() {
+			return false
+		}
+	}
+	return true
+}
+
+func (mp *MinimalProof) Verify() bool {
+	return mp.VerifyError() == nil
+}
+
+// VerifyError is Verify reporting why the proof was rejected, as
+// Proof.VerifyError does.
+func (mp *MinimalProof) VerifyError() error {
+	if !mp.IsComplete() {
+		return ErrIncompleteProof
+	}
+	stmt := &Statement{G: mp.G, H: mp.H, M: mp.M, Z: mp.Z}
+	if err := stmt.check(); err != nil {
+		return err
+	}
+	if !mp.IsSane() {
+		return ErrPointOffCurve
+	}
+	if !mp.hash.Available() || (mp.hash2 != 0 && !mp.hash2.Available()) {
+		return ErrUnknownHash
+	}
+	if !scalarInRange(mp.G.Curve, mp.R) {
+		return ErrScalarRange
+	}
+	if mp.opts.RequireDistinct && !stmt.DistinctPoints() {
+		return ErrRepeatedPoint
+	}
+
+	// The verifier derives c itself rather than being told it, then reuses
+	// the (C, R) computation of the commitments to compare points.
+	pr := &Proof{G: mp.G, M: mp.M, H: mp.H, Z: mp.Z, R: mp.R, params: mp.params}
+	if err := pr.opts.checkChallengeBits(mp.G.Curve); err != nil {
+		return err
+	}
+	if err := pr.opts.checkOrder(); err != nil {
+		return err
+	}
+	pr.C = pr.challenge(mp.A, mp.B)
+	if err := pr.checkScalars(); err != nil {
+		return err
+	}
+	a, b := pr.commitments()
+	if !a.equal(mp.A) || !b.equal(mp.B) {
+		return ErrInvalidProof
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package dleq
+
+import (
+	"crypto"
+	"crypto/elliptic"
+	"math/big"
+	"testing"
+)
+
+func TestValidMinimalProof(t *testing.T) {
+	G, H, M, Z, x := testStatement(t, elliptic.P256())
+	proof, err := NewMinimalProof(crypto.SHA256, G, H, M, Z, x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !proof.Verify() {
+		t.Fatal("proof was invalid")
+	}
+}
+
+func TestMinimalFromProof(t *testing.T) {
+	G, H, M, Z, x := testStatement(t, elliptic.P256())
+	proof, err := NewProof(crypto.SHA256, G, H, M, Z, x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !proof.Minimal().Verify() {
+		t.Fatal("converted proof was invalid")
+	}
+}
+
+func TestInvalidMinimalProof(t *testing.T) {
+	curve := elliptic.P256()
+	G, H, M, Z, x := testStatement(t, curve)
+	proof, err := NewMinimalProof(crypto.SHA256, G, H, M, Z, x)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// swap in a different, valid commitment
+	Ax, Ay := curve.Add(proof.A.X, proof.A.Y, G.X, G.Y)
+	proof.A = &Point{Curve: curve, X: Ax, Y: Ay}
+	if proof.Verify() {
+		t.Fatal("validated a proof with a forged commitment")
+	}
+}
+
+func TestMinimalProofUnreducedResponse(t *testing.T) {
+	curve := elliptic.P256()
+	G, H, M, Z, x := testStatement(t, curve)
+	proof, err := NewMinimalProof(crypto.SHA256, G, H, M, Z, x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	proof.R = new(big.Int).Add(proof.R, curve.Params().N)
+	if proof.Verify() {
+		t.Fatal("validated a proof with an unreduced response")
+	}
+}
+
+func TestMinimalProofSize(t *testing.T) {
+	curve := elliptic.P256()
+	G, H, M, Z, x := testStatement(t, curve)
+	proof, err := NewProof(crypto.SHA256, G, H, M, Z, x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mp := proof.Minimal()
+
+	// Beyond the statement, a Proof sends (C, R) and a MinimalProof sends
+	// (A, B, R).
+	scalarSize := (curve.Params().N.BitLen() + 7) / 8
+	compact := 2 * scalarSize
+	minimal := len(mp.A.Marshal()) + len(mp.B.Marshal()) + scalarSize
+	if compact != 64 || minimal != 162 {
+		t.Fatalf("unexpected sizes: compact %d, minimal %d", compact, minimal)
+	}
+	if minimal <= compact {
+		t.Fatal("commitment form should be the larger encoding")
+	}
+}
+
+func TestMinimalProofRequireDistinct(t *testing.T) {
+	// x = 1 is a true statement with H == G and Z == M.
+	G, _, M, _, _ := testStatement(t, elliptic.P256())
+	proof, err := NewMinimalProof(crypto.SHA256, G, G, M, M, big.NewInt(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !proof.Verify() {
+		t.Fatal("proof was invalid")
+	}
+	proof.opts.RequireDistinct = true
+	if err := proof.VerifyError(); err != ErrRepeatedPoint {
+		t.Fatalf("strict verification: expected ErrRepeatedPoint, got %v", err)
+	}
+}
@@ -0,0 +1,54 @@
+package dleq
+
+import (
+	"crypto/sha256"
+	"sync"
+)
+
+// A SoundnessMonitor watches the proofs a verifier accepts for a commitment
+// that comes back with a different challenge. By special soundness two such
+// proofs reveal the witness, as ExtractWitness shows, so seeing one means
+// the prover reused a nonce: its randomness source is broken and its key
+// should be considered compromised.
+//
+// The monitor remembers a digest of every commitment it has accepted, so it
+// grows with the log it watches. The zero value is ready to use, and a
+// SoundnessMonitor is safe for concurrent use.
+type SoundnessMonitor struct {
+	mu   sync.Mutex
+	seen map[[sha256.Size]byte]string // commitments digest -> challenge
+}
+
+// Check verifies pr and records its commitments. It returns the proof's
+// VerifyError if it is invalid, ErrCommitmentReuse if an earlier proof had
+// the same commitments with a different challenge, and nil otherwise. Only
+// proofs for which it returns nil are recorded, and seeing the same proof
+// twice is not a reuse.
+func (sm *SoundnessMonitor) Check(pr *Proof) error {
+	if err := pr.VerifyError(); err != nil {
+		return err
+	}
+	curve := pr.G.Curve
+	a, b := pr.commitments()
+	digest := sha256.New()
+	digest.Write([]byte(curve.Params().Name))
+	digest.Write(a.Marshal())
+	digest.Write(b.Marshal())
+	var key [sha256.Size]byte
+	digest.Sum(key[:0])
+	challenge := string(scalarBytes(curve, pr.C))
+
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	if seen, ok := sm.seen[key]; ok {
+		if seen != challenge {
+			return ErrCommitmentReuse
+		}
+		return nil
+	}
+	if sm.seen == nil {
+		sm.seen = make(map[[sha256.Size]byte]string)
+	}
+	sm.seen[key] = challenge
+	return nil
+}
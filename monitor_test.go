@@ -0,0 +1,64 @@
+package dleq
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/elliptic"
+	"math/big"
+	"testing"
+)
+
+func TestSoundnessMonitor(t *testing.T) {
+	G, H, M, Z, x := testStatement(t, elliptic.P256())
+	stmt := &Statement{G: G, H: H, M: M, Z: Z}
+
+	// A broken prover that draws the same nonce for two proofs under
+	// different contexts gets different challenges for one commitment.
+	nonce := bytes.Repeat([]byte{0x42}, 32)
+	prove := func(ctx string) *Proof {
+		pr := newProof(crypto.SHA256, stmt, nil)
+		pr.context = []byte(ctx)
+		if _, _, err := pr.prove(x, bytes.NewReader(nonce)); err != nil {
+			t.Fatal(err)
+		}
+		return pr
+	}
+	p1, p2 := prove("first"), prove("second")
+	if p1.C.Cmp(p2.C) == 0 {
+		t.Fatal("contexts gave the same challenge")
+	}
+
+	var monitor SoundnessMonitor
+	if err := monitor.Check(p1); err != nil {
+		t.Fatal(err)
+	}
+	if err := monitor.Check(p1); err != nil {
+		t.Fatalf("the same proof twice was flagged: %v", err)
+	}
+	if err := monitor.Check(p2); err != ErrCommitmentReuse {
+		t.Fatalf("expected ErrCommitmentReuse, got %v", err)
+	}
+
+	// What the monitor guards against: the pair gives up the witness.
+	if extracted, err := ExtractWitness(p1, p2); err != nil || extracted.Cmp(x) != 0 {
+		t.Fatalf("pair did not reveal the witness: %v", err)
+	}
+
+	// An honest proof is fine, and an invalid one is rejected without
+	// being recorded.
+	honest, err := NewProof(crypto.SHA256, G, H, M, Z, x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := monitor.Check(honest); err != nil {
+		t.Fatal(err)
+	}
+	bad := *honest
+	bad.R = new(big.Int).Add(honest.R, big.NewInt(1))
+	if err := monitor.Check(&bad); err != ErrInvalidProof {
+		t.Fatalf("expected ErrInvalidProof, got %v", err)
+	}
+	if len(monitor.seen) != 2 {
+		t.Fatalf("monitor recorded %d commitments, expected 2", len(monitor.seen))
+	}
+}
@@ -0,0 +1,187 @@
+package dleq
+
+import (
+	"crypto/elliptic"
+	"math/big"
+)
+
+// MinChallengeBits is the shortest challenge a proof may be truncated to.
+const MinChallengeBits = 80
+
+// Options adjusts how proofs are computed and encoded. The zero value is the
+// package default, and a nil *Options is the same as the zero value.
+type Options struct {
+	// Endianness is the byte order of scalars, used both when the challenge
+	// is read out of the hash output and when C and R are serialized.
+	Endianness ScalarEndianness
+
+	// Convention is the sign of the witness term in the response.
+	Convention ResponseConvention
+
+	// ChallengeBits truncates the challenge to its low ChallengeBits bits,
+	// which shrinks C on the wire at a direct cost in soundness: a prover
+	// that doesn't know the witness can answer a truncated challenge with
+	// probability 2^-ChallengeBits, against about 1/q for the full one. It
+	// has to be at least MinChallengeBits and less than the bit length of
+	// the group order. Zero means the full challenge.
+	ChallengeBits int
+
+	// BindParameters starts the transcript with the curve name and the hash
+	// identifier, so that the challenge commits to the exact parameters the
+	// proof was made under and can't be reinterpreted under others that
+	// happen to encode the points the same way. It is recommended for new
+	// protocols; it's off by default only for compatibility.
+	BindParameters bool
+
+	// TranscriptOrder is the order the statement's points are hashed in
+	// ahead of the commitments, for matching the transcript of another
+	// implementation.
+	TranscriptOrder TranscriptOrder
+
+	// RequireDistinct rejects statements in which any two of G, H, M and Z
+	// are equal, both when proving and when verifying. It is local policy
+	// rather than part of the proof, so it isn't serialized.
+	RequireDistinct bool
+
+	// SelfVerify verifies every proof right after building it and fails
+	// with ErrSelfVerify instead of returning one that doesn't verify, as a
+	// buggy curve implementation can produce. It roughly doubles the cost
+	// of proving. Like RequireDistinct it isn't serialized.
+	SelfVerify bool
+}
+
+// ResponseConvention selects how the response folds in the witness.
+type ResponseConvention int
+
+const (
+	// Subtractive responses are r = s - cx, checked as a = rG + cH. This
+	// saves the verifier an inversion and is the default.
+	Subtractive ResponseConvention = iota
+
+	// Additive responses are r = s + cx, checked as a = rG - cH. This is
+	// the textbook form most other implementations use.
+	Additive
+)
+
+// responseChallenge is the challenge as it enters the response: c for the
+// subtractive convention and -c for the additive one, since
+// s + cx = s - (-c)x. With it the subtractive formulas serve both.
+func (opts *Options) responseChallenge(curve elliptic.Curve, c *big.Int) *big.Int {
+	if opts.Convention != Additive {
+		return c
+	}
+	N := curve.Params().N
+	neg := new(big.Int).Neg(c)
+	return neg.Mod(neg, N)
+}
+
+// TranscriptOrder selects the sequence of points in the challenge hash.
+type TranscriptOrder int
+
+const (
+	// TranscriptGHMZ hashes G, H, M, Z, a, b. It is the default.
+	TranscriptGHMZ TranscriptOrder = iota
+
+	// TranscriptGMHZ hashes both generators first: G, M, H, Z, a, b.
+	TranscriptGMHZ
+
+	// TranscriptMZ hashes M, Z, a, b, as the Chaum-Pedersen paper does.
+	// The challenge then commits to neither G nor H, which is sound only
+	// if the verifier fixes both independently of the proof; use it just
+	// to match implementations that leave them out.
+	TranscriptMZ
+)
+
+// points lists the statement's points in the order o hashes them, or
+// returns nil if o is unknown.
+func (o TranscriptOrder) points(g, h, m, z *Point) []*Point {
+	switch o {
+	case TranscriptGHMZ:
+		return []*Point{g, h, m, z}
+	case TranscriptGMHZ:
+		return []*Point{g, m, h, z}
+	case TranscriptMZ:
+		return []*Point{m, z}
+	}
+	return nil
+}
+
+// checkOrder validates TranscriptOrder.
+func (opts *Options) checkOrder() error {
+	if opts.TranscriptOrder < TranscriptGHMZ || opts.TranscriptOrder > TranscriptMZ {
+		return ErrTranscriptOrder
+	}
+	return nil
+}
+
+// checkChallengeBits validates ChallengeBits for curve.
+func (opts *Options) checkChallengeBits(curve elliptic.Curve) error {
+	k := opts.ChallengeBits
+	if k != 0 && (k < MinChallengeBits || k >= curve.Params().N.BitLen()) {
+		return ErrChallengeBits
+	}
+	return nil
+}
+
+// truncate cuts a reduced challenge down to ChallengeBits, in place.
+func (opts *Options) truncate(c *big.Int) *big.Int {
+	if opts.ChallengeBits == 0 {
+		return c
+	}
+	mask := new(big.Int).Lsh(big.NewInt(1), uint(opts.ChallengeBits))
+	return c.And(c, mask.Sub(mask, big.NewInt(1)))
+}
+
+// challengeFits reports whether c is short enough to be a challenge.
+func (opts *Options) challengeFits(c *big.Int) bool {
+	return opts.ChallengeBits == 0 || c.BitLen() <= opts.ChallengeBits
+}
+
+// challengeSize is the encoded length of C: that of the group order, or of
+// ChallengeBits if the challenge is truncated.
+func (opts *Options) challengeSize(curve elliptic.Curve) int {
+	if opts.ChallengeBits != 0 {
+		return (opts.ChallengeBits + 7) / 8
+	}
+	return ScalarLen(curve)
+}
+
+// ScalarEndianness is the byte order used to encode scalars.
+type ScalarEndianness int
+
+const (
+	// BigEndian matches big.Int.Bytes and SEC 1. It is the default.
+	BigEndian ScalarEndianness = iota
+
+	// LittleEndian is for interoperating with implementations in the
+	// curve25519 tradition, which encode scalars little-endian.
+	LittleEndian
+)
+
+// encode returns k padded to the byte length of the group order.
+func (e ScalarEndianness) encode(curve elliptic.Curve, k *big.Int) []byte {
+	return e.encodeSize(k, ScalarLen(curve))
+}
+
+// encodeSize returns k padded to size bytes, which it must fit in.
+func (e ScalarEndianness) encodeSize(k *big.Int, size int) []byte {
+	out := k.FillBytes(make([]byte, size))
+	if e == LittleEndian {
+		reverse(out)
+	}
+	return out
+}
+
+func (e ScalarEndianness) decode(data []byte) *big.Int {
+	if e == LittleEndian {
+		data = append([]byte{}, data...)
+		reverse(data)
+	}
+	return new(big.Int).SetBytes(data)
+}
+
+func reverse(b []byte) {
+	for i, j := 0, len(b)-1; i < j; i, j = i+1, j-1 {
+		b[i], b[j] = b[j], b[i]
+	}
+}
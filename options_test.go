@@ -0,0 +1,363 @@
+package dleq
+
+import (
+	"crypto"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+)
+
+// A P-256 proof with G the base point, M = 2G, x = 0x1234567, points
+// compressed and scalars little-endian.
+const littleEndianFixture = "0102050102036b17d1f2e12c4247f8bce6e563a440f277037d812deb33a0f4a13945d898c2960202088bb9ff22ab291a74c86fc677ba897baadee370cc6129b82d170ba3fc26415c02037cf27b188d034f7e8a52380304b51ac3c08969e277f21b35a60b48fc476699780202b0cdeeae5933a06851b5212af4b54cae6e3b786e9848c7ffa5e8775e139b10c620e6fe28f5f9235dbab718417f660477172c8045c004aec0faf9b5827025fe76b90d8df1696646dcc252f6bdf12eeaf88d6bfea07885676ec7752877c0e67d52"
+
+func TestEndiannessRoundTrip(t *testing.T) {
+	G, H, M, Z, x := testStatement(t, elliptic.P256())
+	for _, endian := range []ScalarEndianness{BigEndian, LittleEndian} {
+		proof, err := NewProofWithOptions(crypto.SHA256, G, H, M, Z, x, &Options{Endianness: endian})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !proof.Verify() {
+			t.Fatalf("endianness %d: proof was invalid", endian)
+		}
+
+		data, err := proof.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		decoded := new(Proof)
+		if err := decoded.UnmarshalBinary(data); err != nil {
+			t.Fatal(err)
+		}
+		if decoded.opts.Endianness != endian || !decoded.Verify() {
+			t.Fatalf("endianness %d: binary round trip failed", endian)
+		}
+
+		data, err = json.Marshal(proof)
+		if err != nil {
+			t.Fatal(err)
+		}
+		decoded = new(Proof)
+		if err := json.Unmarshal(data, decoded); err != nil {
+			t.Fatal(err)
+		}
+		if decoded.opts.Endianness != endian || !decoded.Verify() {
+			t.Fatalf("endianness %d: JSON round trip failed", endian)
+		}
+	}
+}
+
+func TestLittleEndianFixture(t *testing.T) {
+	data, err := hex.DecodeString(littleEndianFixture)
+	if err != nil {
+		t.Fatal(err)
+	}
+	proof := new(Proof)
+	if err := proof.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if proof.opts.Endianness != LittleEndian {
+		t.Fatal("fixture was not read as little-endian")
+	}
+	if !proof.Verify() {
+		t.Fatal("fixture proof was invalid")
+	}
+
+	// Reading the same bytes as big-endian must not verify.
+	data[3] &^= flagLittleEndian
+	proof = new(Proof)
+	if err := proof.UnmarshalBinary(data); err == nil && proof.Verify() {
+		t.Fatal("fixture verified with the wrong endianness")
+	}
+}
+
+func TestResponseConventions(t *testing.T) {
+	G, H, M, Z, x := testStatement(t, elliptic.P256())
+	for _, convention := range []ResponseConvention{Subtractive, Additive} {
+		proof, err := NewProofWithOptions(crypto.SHA256, G, H, M, Z, x, &Options{Convention: convention})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !proof.Verify() {
+			t.Fatalf("convention %d: proof was invalid", convention)
+		}
+
+		data, err := proof.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		decoded := new(Proof)
+		if err := decoded.UnmarshalBinary(data); err != nil {
+			t.Fatal(err)
+		}
+		if decoded.opts.Convention != convention || !decoded.Verify() {
+			t.Fatalf("convention %d: binary round trip failed", convention)
+		}
+
+		// A verifier expecting the other convention rejects it.
+		decoded.opts.Convention = 1 - convention
+		if decoded.Verify() {
+			t.Fatalf("convention %d: verified under the wrong convention", convention)
+		}
+	}
+}
+
+func TestAdditiveResponseIsTextbook(t *testing.T) {
+	curve := elliptic.P256()
+	G, H, M, Z, x := testStatement(t, curve)
+	proof, err := NewProofWithOptions(crypto.SHA256, G, H, M, Z, x, &Options{Convention: Additive})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Check a = rG - cH by hand, as another implementation would, and
+	// that it reproduces the challenge.
+	negC := new(big.Int).Sub(curve.Params().N, proof.C)
+	rGx, rGy := curve.ScalarMult(G.X, G.Y, proof.R.Bytes())
+	cHx, cHy := curve.ScalarMult(H.X, H.Y, negC.Bytes())
+	Ax, Ay := curve.Add(rGx, rGy, cHx, cHy)
+	rMx, rMy := curve.ScalarMult(M.X, M.Y, proof.R.Bytes())
+	cZx, cZy := curve.ScalarMult(Z.X, Z.Y, negC.Bytes())
+	Bx, By := curve.Add(rMx, rMy, cZx, cZy)
+
+	a := &Point{Curve: curve, X: Ax, Y: Ay}
+	b := &Point{Curve: curve, X: Bx, Y: By}
+	if proof.challenge(a, b).Cmp(proof.C) != 0 {
+		t.Fatal("additive proof doesn't satisfy a = rG - cH")
+	}
+}
+
+func TestTruncatedChallenge(t *testing.T) {
+	G, H, M, Z, x := testStatement(t, elliptic.P256())
+	opts := &Options{ChallengeBits: 128}
+	proof, err := NewProofWithOptions(crypto.SHA256, G, H, M, Z, x, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if proof.C.BitLen() > 128 {
+		t.Fatalf("challenge has %d bits", proof.C.BitLen())
+	}
+	if !proof.Verify() {
+		t.Fatal("truncated proof was invalid")
+	}
+
+	full, err := NewProof(crypto.SHA256, G, H, M, Z, x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	short, err := proof.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	long, err := full.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// C shrinks from 32 bytes to 16, less the 2 announcing the length.
+	if len(long)-len(short) != 14 {
+		t.Fatalf("truncation saved %d bytes, expected 14", len(long)-len(short))
+	}
+	decoded := new(Proof)
+	if err := decoded.UnmarshalBinary(short); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.opts.ChallengeBits != 128 || !decoded.Verify() {
+		t.Fatal("binary round trip lost the truncation")
+	}
+
+	data, err := json.Marshal(proof)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fromJSON := new(Proof)
+	if err := json.Unmarshal(data, fromJSON); err != nil {
+		t.Fatal(err)
+	}
+	if !fromJSON.Verify() {
+		t.Fatal("JSON round trip lost the truncation")
+	}
+}
+
+func TestTruncatedChallengeIsSymmetric(t *testing.T) {
+	G, H, M, Z, x := testStatement(t, elliptic.P256())
+	proof, err := NewProofWithOptions(crypto.SHA256, G, H, M, Z, x, &Options{ChallengeBits: 128})
+	if err != nil {
+		t.Fatal(err)
+	}
+	full, err := NewProof(crypto.SHA256, G, H, M, Z, x)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Each side has to truncate the same way.
+	proof.opts.ChallengeBits = 0
+	if proof.Verify() {
+		t.Fatal("truncated proof verified without truncation")
+	}
+	proof.opts.ChallengeBits = 96
+	if proof.Verify() {
+		t.Fatal("truncated proof verified at a different length")
+	}
+	full.opts.ChallengeBits = 128
+	if full.Verify() {
+		t.Fatal("full proof verified with truncation")
+	}
+}
+
+func TestChallengeBitsRange(t *testing.T) {
+	G, H, M, Z, x := testStatement(t, elliptic.P256())
+	for _, bits := range []int{1, MinChallengeBits - 1, 256, 300} {
+		_, err := NewProofWithOptions(crypto.SHA256, G, H, M, Z, x, &Options{ChallengeBits: bits})
+		if err != ErrChallengeBits {
+			t.Errorf("%d bits: expected ErrChallengeBits, got %v", bits, err)
+		}
+	}
+	for _, bits := range []int{MinChallengeBits, 255} {
+		proof, err := NewProofWithOptions(crypto.SHA256, G, H, M, Z, x, &Options{ChallengeBits: bits})
+		if err != nil {
+			t.Fatalf("%d bits: %v", bits, err)
+		}
+		if !proof.Verify() {
+			t.Fatalf("%d bits: proof was invalid", bits)
+		}
+	}
+}
+
+func TestBindParameters(t *testing.T) {
+	curve := elliptic.P256()
+	G, H, M, Z, x := testStatement(t, curve)
+	bound, err := NewProofWithOptions(crypto.SHA256, G, H, M, Z, x, &Options{BindParameters: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	unbound, err := NewProof(crypto.SHA256, G, H, M, Z, x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bound.Verify() {
+		t.Fatal("bound proof was invalid")
+	}
+
+	// An unnamed copy of P-256 encodes every point identically, so only a
+	// transcript that binds the curve tells the two apart.
+	alias := copyCurve(curve)
+	alias.Name = ""
+	reinterpret := func(pr *Proof) *Proof {
+		out := *pr
+		onAlias := func(p *Point) *Point { return &Point{Curve: alias, X: p.X, Y: p.Y} }
+		out.G, out.H, out.M, out.Z = onAlias(pr.G), onAlias(pr.H), onAlias(pr.M), onAlias(pr.Z)
+		return &out
+	}
+	if !reinterpret(unbound).Verify() {
+		t.Fatal("unbound proof should verify under the alias")
+	}
+	if reinterpret(bound).Verify() {
+		t.Fatal("bound proof verified under different declared parameters")
+	}
+
+	// Dropping the option on either side breaks the proof.
+	stripped := *bound
+	stripped.opts.BindParameters = false
+	if stripped.Verify() {
+		t.Fatal("bound proof verified without binding")
+	}
+
+	for _, marshal := range []func(*Proof) ([]byte, error){
+		(*Proof).MarshalBinary,
+		func(pr *Proof) ([]byte, error) { return json.Marshal(pr) },
+	} {
+		data, err := marshal(bound)
+		if err != nil {
+			t.Fatal(err)
+		}
+		decoded := new(Proof)
+		if data[0] == '{' {
+			err = json.Unmarshal(data, decoded)
+		} else {
+			err = decoded.UnmarshalBinary(data)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !decoded.opts.BindParameters || !decoded.Verify() {
+			t.Fatal("round trip lost the parameter binding")
+		}
+	}
+}
+
+func TestTranscriptOrder(t *testing.T) {
+	curve := elliptic.P256()
+	G, H, M, Z, x := testStatement(t, curve)
+	orders := []TranscriptOrder{TranscriptGHMZ, TranscriptGMHZ, TranscriptMZ}
+	for _, order := range orders {
+		proof, err := NewProofWithOptions(crypto.SHA256, G, H, M, Z, x, &Options{TranscriptOrder: order})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !proof.Verify() {
+			t.Fatalf("order %d: proof was invalid", order)
+		}
+
+		// A verifier hashing in any other order rejects it.
+		for _, other := range orders {
+			if other == order {
+				continue
+			}
+			mismatched := *proof
+			mismatched.opts.TranscriptOrder = other
+			if mismatched.Verify() {
+				t.Errorf("proof made with order %d verified under order %d", order, other)
+			}
+		}
+
+		for _, marshal := range []func(*Proof) ([]byte, error){
+			(*Proof).MarshalBinary,
+			func(pr *Proof) ([]byte, error) { return json.Marshal(pr) },
+		} {
+			data, err := marshal(proof)
+			if err != nil {
+				t.Fatal(err)
+			}
+			decoded := new(Proof)
+			if data[0] == '{' {
+				err = json.Unmarshal(data, decoded)
+			} else {
+				err = decoded.UnmarshalBinary(data)
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if decoded.opts.TranscriptOrder != order || !decoded.Verify() {
+				t.Fatalf("order %d: round trip lost the transcript order", order)
+			}
+		}
+	}
+
+	// The paper's order is exactly H(M, Z, a, b).
+	proof := newProof(crypto.SHA256, &Statement{G: G, H: H, M: M, Z: Z}, &Options{TranscriptOrder: TranscriptMZ})
+	a, b, err := proof.prove(x, rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	digest := sha256.New()
+	for _, p := range []*Point{M, Z, a, b} {
+		digest.Write(p.Marshal())
+	}
+	c := new(big.Int).SetBytes(digest.Sum(nil))
+	if c.Mod(c, curve.Params().N).Cmp(proof.C) != 0 {
+		t.Fatal("TranscriptMZ challenge is not H(M, Z, a, b)")
+	}
+
+	if _, err := NewProofWithOptions(crypto.SHA256, G, H, M, Z, x, &Options{TranscriptOrder: 3}); err != ErrTranscriptOrder {
+		t.Fatalf("expected ErrTranscriptOrder, got %v", err)
+	}
+	if err := ValidateJSON([]byte(`{"curve": "P-256", "hash": "SHA-256", "transcriptOrder": "ZMHG"}`)); !errors.Is(err, ErrTranscriptOrder) {
+		t.Fatalf("expected ErrTranscriptOrder, got %v", err)
+	}
+}
@@ -0,0 +1,275 @@
+package dleq
+
+import (
+	"crypto"
+	"crypto/elliptic"
+	crand "crypto/rand"
+	"crypto/sha256"
+	"io"
+	"math/big"
+)
+
+// A Commitment is the prover's first message (a, b) = (g^s, m^s) in the
+// interactive protocol.
+type Commitment struct {
+	A, B *Point
+}
+
+// A ProverState carries the nonce s between the prover's commitment and its
+// response, so that the three-move protocol can span network round trips or
+// process restarts.
+//
+// The nonce is as sensitive as the witness itself: anyone holding s and the
+// eventual response can solve for x, and answering two different challenges
+// with the same s reveals x to the verifier. A marshaled ProverState must be
+// stored encrypted with access limited as it would be for x, and must be
+// deleted before the response is released so that a restored copy can never
+// be finalized a second time. Finalize wipes the in-memory nonce.
+type ProverState struct {
+	curve elliptic.Curve
+	s     *big.Int
+}
+
+// A Prover holds a witness x and produces proofs for statements about it.
+// It reuses scratch space between proofs, so it must not be used from more
+// than one goroutine at a time.
+type Prover struct {
+	// Rand is the source of nonces. If nil, crypto/rand.Reader is used. If
+	// it is a SecureRand, it has to pass its health check for every nonce.
+	Rand io.Reader
+
+	hash   crypto.Hash
+	x      *big.Int
+	nonces *nonceLog
+	arena  arena
+}
+
+// An arena holds the big.Ints a Prover reuses for the arithmetic inside
+// Prove, so that a high-volume prover doesn't allocate them per proof. None
+// of them ends up in a Proof, and the nonce is wiped after each use.
+type arena struct {
+	nonce []byte  // s, as passed to the curve
+	s     big.Int // the nonce
+	t, q  big.Int // cx mod N, and the quotient it leaves behind
+}
+
+// response is ComputeResponse with its intermediates kept in the arena. The
+// result is a fresh big.Int that the caller can keep.
+func (ar *arena) response(s, c, x, N *big.Int) *big.Int {
+	ar.t.Mul(c, x)
+	ar.q.QuoRem(&ar.t, N, &ar.t)
+	if ar.t.Sign() < 0 {
+		ar.t.Add(&ar.t, N)
+	}
+	r := new(big.Int).Sub(s, &ar.t) // r = s - cx, in (-N, N)
+	if r.Sign() < 0 {
+		r.Add(r, N)
+	}
+	return r
+}
+
+// wipe clears the arena's copies of the nonce and the secret product.
+func (ar *arena) wipe() {
+	for i := range ar.nonce {
+		ar.nonce[i] = 0
+	}
+	for _, k := range []*big.Int{&ar.s, &ar.t, &ar.q} {
+		words := k.Bits()
+		for i := range words {
+			words[i] = 0
+		}
+		k.SetInt64(0)
+	}
+}
+
+func NewProver(hash crypto.Hash, x *big.Int) *Prover {
+	return &Prover{hash: hash, x: x}
+}
+
+func (p *Prover) rand() io.Reader {
+	if p.Rand != nil {
+		return p.Rand
+	}
+	return crand.Reader
+}
+
+// TrackNonces makes the prover remember the last n nonces it used and fail
+// with ErrNonceReuse rather than ever use one of them again. Proofs under
+// the same nonce and witness reveal the witness, so a repeat means the
+// randomness source has failed catastrophically. This is defense in depth
+// and only catches repeats within the window; a non-positive n turns
+// tracking off.
+func (p *Prover) TrackNonces(n int) {
+	if n <= 0 {
+		p.nonces = nil
+		return
+	}
+	p.nonces = &nonceLog{
+		seen: make(map[[sha256.Size]byte]bool, n),
+		ring: make([][sha256.Size]byte, n),
+	}
+}
+
+// commit is the package-level commit with nonce tracking applied. If ar is
+// not nil the nonce is sampled into it rather than a fresh big.Int.
+func (p *Prover) commit(stmt *Statement, ar *arena) (*big.Int, *Point, *Point, error) {
+	curve := stmt.G.Curve
+	var s *big.Int
+	var a, b *Point
+	var err error
+	if ar == nil {
+		s, a, b, err = commit(curve, stmt.G, stmt.M, p.rand())
+	} else {
+		s = &ar.s
+		ar.nonce, a, b, err = commitInto(curve, stmt.G, stmt.M, p.rand(), ar.nonce, s)
+	}
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if p.nonces != nil && !p.nonces.add(curve, s) {
+		return nil, nil, nil, ErrNonceReuse
+	}
+	return s, a, b, nil
+}
+
+// Prove produces a non-interactive proof of stmt.
+func (p *Prover) Prove(stmt *Statement) (*Proof, error) {
+	if err := stmt.check(); err != nil {
+		return nil, err
+	}
+	if !p.hash.Available() {
+		return nil, ErrUnknownHash
+	}
+	proof := newProof(p.hash, stmt, nil)
+	if err := p.prove(proof); err != nil {
+		return nil, err
+	}
+	return proof, nil
+}
+
+// prove fills in C and R of a proof template using the arena.
+func (p *Prover) prove(proof *Proof) error {
+	defer p.arena.wipe()
+	s, a, b, err := p.commit(proof.Statement(), &p.arena)
+	if err != nil {
+		return err
+	}
+	proof.finish(p.x, s, a, b, &p.arena)
+	return proof.selfVerify()
+}
+
+// Commit is the first move of the interactive protocol. The commitment is
+// sent to the verifier; the state is kept, secretly, until the verifier's
+// challenge arrives.
+func (p *Prover) Commit(stmt *Statement) (*Commitment, *ProverState, error) {
+	if err := stmt.check(); err != nil {
+		return nil, nil, err
+	}
+	s, a, b, err := p.commit(stmt, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &Commitment{A: a, B: b}, &ProverState{curve: stmt.G.Curve, s: s}, nil
+}
+
+// Finalize is the last move of the interactive protocol, computing the
+// response to the verifier's challenge c. A state can only be finalized once.
+func (p *Prover) Finalize(state *ProverState, c *big.Int) (*big.Int, error) {
+	if state.s == nil {
+		return nil, ErrStateConsumed
+	}
+	if !scalarInRange(state.curve, c) {
+		return nil, ErrChallengeInvalid
+	}
+	r := ComputeResponse(state.s, c, p.x, state.curve.Params().N)
+	state.wipe()
+	return r, nil
+}
+
+// A nonceLog is a bounded set of recently used nonces. It holds digests
+// rather than commitment points because the same nonce under different
+// generators makes different commitments, and it's the nonce that must not
+// repeat. The digests never leave memory.
+type nonceLog struct {
+	seen map[[sha256.Size]byte]bool
+	ring [][sha256.Size]byte
+	next int
+	full bool
+}
+
+// add records s, reporting false if it was already present.
+func (l *nonceLog) add(curve elliptic.Curve, s *big.Int) bool {
+	digest := sha256.Sum256(scalarBytes(curve, s))
+	if l.seen[digest] {
+		return false
+	}
+	if l.full {
+		delete(l.seen, l.ring[l.next])
+	}
+	l.seen[digest] = true
+	l.ring[l.next] = digest
+	l.next = (l.next + 1) % len(l.ring)
+	if l.next == 0 {
+		l.full = true
+	}
+	return true
+}
+
+func (state *ProverState) wipe() {
+	words := state.s.Bits()
+	for i := range words {
+		words[i] = 0
+	}
+	state.s = nil
+}
+
+// MarshalBinary encodes the state as the curve identifier followed by the
+// nonce. See the ProverState documentation before writing this anywhere.
+func (state *ProverState) MarshalBinary() ([]byte, error) {
+	if state.s == nil {
+		return nil, ErrStateConsumed
+	}
+	id, ok := curveID(state.curve)
+	if !ok {
+		return nil, ErrUnknownCurve
+	}
+	return append([]byte{id}, scalarBytes(state.curve, state.s)...), nil
+}
+
+func (state *ProverState) UnmarshalBinary(data []byte) error {
+	if len(data) < 1 {
+		return ErrMalformedProof
+	}
+	curve, ok := curveByID(data[0])
+	if !ok {
+		return ErrUnknownCurve
+	}
+	if len(data) != 1+ScalarLen(curve) {
+		return ErrMalformedProof
+	}
+	s := new(big.Int).SetBytes(data[1:])
+	if !scalarInRange(curve, s) {
+		return ErrMalformedProof
+	}
+	state.curve, state.s = curve, s
+	return nil
+}
+
+// VerifyResponse is the verifier's check in the interactive protocol: given
+// the prover's commitment, the challenge c it was sent and the response r, it
+// checks that a = rG + cH and b = rM + cZ.
+func (stmt *Statement) VerifyResponse(cm *Commitment, c, r *big.Int) bool {
+	if stmt == nil || cm == nil || stmt.check() != nil || cm.A == nil || cm.B == nil || c == nil || r == nil {
+		return false
+	}
+	curve := stmt.G.Curve
+	if !SameCurve(cm.A.Curve, curve) || !SameCurve(cm.B.Curve, curve) {
+		return false
+	}
+	if !scalarInRange(curve, c) || !scalarInRange(curve, r) {
+		return false
+	}
+	pr := &Proof{G: stmt.G, M: stmt.M, H: stmt.H, Z: stmt.Z, R: r, C: c}
+	a, b := pr.commitments()
+	return a.equal(cm.A) && b.equal(cm.B)
+}
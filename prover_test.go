@@ -0,0 +1,224 @@
+package dleq
+
+import (
+	"crypto"
+	"crypto/elliptic"
+	"crypto/rand"
+	"math/big"
+	"testing"
+)
+
+func TestProverProve(t *testing.T) {
+	G, H, M, Z, x := testStatement(t, elliptic.P256())
+	proof, err := NewProver(crypto.SHA256, x).Prove(&Statement{G: G, H: H, M: M, Z: Z})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !proof.Verify() {
+		t.Fatal("proof was invalid")
+	}
+}
+
+func TestInteractiveAcrossRestart(t *testing.T) {
+	curve := elliptic.P256()
+	G, H, M, Z, x := testStatement(t, curve)
+	stmt := &Statement{G: G, H: H, M: M, Z: Z}
+	prover := NewProver(crypto.SHA256, x)
+
+	commitment, state, err := prover.Commit(stmt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	saved, err := state.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// ...the process restarts and the coordinator's challenge arrives...
+	_, c, err := randScalar(curve, rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	restored := new(ProverState)
+	if err := restored.UnmarshalBinary(saved); err != nil {
+		t.Fatal(err)
+	}
+	r, err := prover.Finalize(restored, c)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !stmt.VerifyResponse(commitment, c, r) {
+		t.Fatal("response was invalid")
+	}
+	if _, err := prover.Finalize(restored, c); err != ErrStateConsumed {
+		t.Fatalf("expected ErrStateConsumed, got %v", err)
+	}
+}
+
+func TestInteractiveWrongChallenge(t *testing.T) {
+	curve := elliptic.P256()
+	G, H, M, Z, x := testStatement(t, curve)
+	stmt := &Statement{G: G, H: H, M: M, Z: Z}
+	prover := NewProver(crypto.SHA256, x)
+
+	commitment, state, err := prover.Commit(stmt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, c, err := randScalar(curve, rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, other, err := randScalar(curve, rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := prover.Finalize(state, c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stmt.VerifyResponse(commitment, other, r) {
+		t.Fatal("response verified against a different challenge")
+	}
+	if stmt.VerifyResponse(nil, c, r) || stmt.VerifyResponse(&Commitment{}, c, r) {
+		t.Fatal("response verified without a commitment")
+	}
+	var nilStmt *Statement
+	if nilStmt.VerifyResponse(commitment, c, r) {
+		t.Fatal("response verified without a statement")
+	}
+}
+
+// riggedReader hands out the same bytes every time it's read.
+type riggedReader []byte
+
+func (r riggedReader) Read(p []byte) (int, error) {
+	return copy(p, r), nil
+}
+
+func TestProverDetectsNonceReuse(t *testing.T) {
+	curve := elliptic.P256()
+	G, H, M, Z, x := testStatement(t, curve)
+	other, _, _, _, _ := testStatement(t, curve)
+
+	prover := NewProver(crypto.SHA256, x)
+	nonce := make([]byte, 32)
+	nonce[31] = 1
+	prover.Rand = riggedReader(nonce)
+	prover.TrackNonces(16)
+
+	if _, err := prover.Prove(&Statement{G: G, H: H, M: M, Z: Z}); err != nil {
+		t.Fatal(err)
+	}
+	// A different statement, but the RNG repeats itself.
+	Hx, Hy := curve.ScalarMult(other.X, other.Y, x.Bytes())
+	second := &Statement{G: other, H: &Point{Curve: curve, X: Hx, Y: Hy}, M: M, Z: Z}
+	if _, err := prover.Prove(second); err != ErrNonceReuse {
+		t.Fatalf("expected ErrNonceReuse, got %v", err)
+	}
+	if _, _, err := prover.Commit(second); err != ErrNonceReuse {
+		t.Fatalf("expected ErrNonceReuse from Commit, got %v", err)
+	}
+}
+
+func TestNonceLogIsBounded(t *testing.T) {
+	curve := elliptic.P256()
+	log := &nonceLog{
+		seen: make(map[[32]byte]bool),
+		ring: make([][32]byte, 2),
+	}
+	one, two, three := big.NewInt(1), big.NewInt(2), big.NewInt(3)
+	for _, s := range []*big.Int{one, two, three} {
+		if !log.add(curve, s) {
+			t.Fatalf("fresh nonce %v reported as reused", s)
+		}
+	}
+	if len(log.seen) != 2 {
+		t.Fatalf("log holds %d nonces, expected 2", len(log.seen))
+	}
+	// one has aged out of the window, three hasn't
+	if !log.add(curve, one) {
+		t.Fatal("nonce outside the window reported as reused")
+	}
+	if log.add(curve, three) {
+		t.Fatal("nonce inside the window not detected")
+	}
+}
+
+func TestProverArenaResponse(t *testing.T) {
+	curve := elliptic.P256()
+	N := curve.Params().N
+	var ar arena
+	for i := 0; i < 100; i++ {
+		_, s, _ := randScalar(curve, rand.Reader)
+		_, c, _ := randScalar(curve, rand.Reader)
+		_, x, _ := randScalar(curve, rand.Reader)
+		if i%2 == 1 {
+			x.Neg(x)
+		}
+		if got, want := ar.response(s, c, x, N), ComputeResponse(s, c, x, N); got.Cmp(want) != 0 {
+			t.Fatalf("arena response %v, expected %v", got, want)
+		}
+	}
+}
+
+func TestProverArenaIsNotShared(t *testing.T) {
+	G, H, M, Z, x := testStatement(t, elliptic.P256())
+	stmt := &Statement{G: G, H: H, M: M, Z: Z}
+	prover := NewProver(crypto.SHA256, x)
+	first, err := prover.Prove(stmt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := new(big.Int).Set(first.R)
+	second, err := prover.Prove(stmt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.R.Cmp(r) != 0 {
+		t.Fatal("a later proof overwrote an earlier one's response")
+	}
+	if !first.Verify() || !second.Verify() {
+		t.Fatal("proof was invalid")
+	}
+	if prover.arena.s.Sign() != 0 {
+		t.Fatal("nonce was left in the arena")
+	}
+}
+
+func TestProverProveAllocations(t *testing.T) {
+	// The curve operations dominate either way; the arena only removes the
+	// nonce and response intermediates, but it must remove them.
+	G, H, M, Z, x := testStatement(t, elliptic.P256())
+	stmt := &Statement{G: G, H: H, M: M, Z: Z}
+	prover := NewProver(crypto.SHA256, x)
+	prover.Prove(stmt) // warm the arena
+	withArena := testing.AllocsPerRun(100, func() { prover.Prove(stmt) })
+	without := testing.AllocsPerRun(100, func() { NewProof(crypto.SHA256, G, H, M, Z, x) })
+	if withArena >= without {
+		t.Fatalf("Prove made %.0f allocations, NewProof %.0f", withArena, without)
+	}
+}
+
+func BenchmarkNewProof(b *testing.B) {
+	G, H, M, Z, x := testStatement(b, elliptic.P256())
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := NewProof(crypto.SHA256, G, H, M, Z, x); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkProverProve(b *testing.B) {
+	G, H, M, Z, x := testStatement(b, elliptic.P256())
+	stmt := &Statement{G: G, H: H, M: M, Z: Z}
+	prover := NewProver(crypto.SHA256, x)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := prover.Prove(stmt); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package dleq
+
+import (
+	"encoding/base32"
+)
+
+// qrEncoding is unpadded RFC 4648 base32. Its alphabet of A-Z and 2-7 sits
+// inside the QR alphanumeric character set, which packs 5.5 bits per
+// character against 8 for byte mode.
+var qrEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// MarshalQR encodes the proof for a QR code, for verification setups where
+// proofs have to be scanned rather than sent. The payload is the binary
+// format with all points compressed.
+func (pr *Proof) MarshalQR() (string, error) {
+	data, err := pr.MarshalBinaryEncoding(Compressed, Compressed)
+	if err != nil {
+		return "", err
+	}
+	return qrEncoding.EncodeToString(data), nil
+}
+
+// ParseQR decodes a proof encoded by MarshalQR.
+func ParseQR(s string) (*Proof, error) {
+	if qrEncoding.DecodedLen(len(s)) > DefaultMaxProofSize {
+		return nil, ErrProofTooLarge
+	}
+	data, err := qrEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrMalformedProof
+	}
+	proof := new(Proof)
+	if err := proof.UnmarshalBinary(data); err != nil {
+		return nil, err
+	}
+	return proof, nil
+}
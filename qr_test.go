@@ -0,0 +1,42 @@
+package dleq
+
+import (
+	"crypto"
+	"crypto/elliptic"
+	"strings"
+	"testing"
+)
+
+func TestProofQRRoundTrip(t *testing.T) {
+	G, H, M, Z, x := testStatement(t, elliptic.P256())
+	proof, err := NewProof(crypto.SHA256, G, H, M, Z, x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := proof.MarshalQR()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Everything must be in the QR alphanumeric set.
+	const alphanumeric = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ $%*+-./:"
+	for _, r := range s {
+		if !strings.ContainsRune(alphanumeric, r) {
+			t.Fatalf("character %q is outside the QR alphanumeric set", r)
+		}
+	}
+
+	decoded, err := ParseQR(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !decoded.Verify() {
+		t.Fatal("decoded proof was invalid")
+	}
+}
+
+func TestParseQRRejectsGarbage(t *testing.T) {
+	if _, err := ParseQR("not base32!"); err != ErrMalformedProof {
+		t.Fatalf("expected ErrMalformedProof, got %v", err)
+	}
+}
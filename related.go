@@ -0,0 +1,45 @@
+package dleq
+
+import (
+	"crypto"
+	"math/big"
+)
+
+// NewProofRelatedGenerators proves that H = xG and Z = xM for a second
+// generator M = tG that is a public multiple of g. It derives M, H and Z
+// itself, and the verifier rebuilds M from t with VerifyRelatedGenerators
+// rather than trusting the one in the proof, so a protocol can send t in
+// its place.
+//
+// Since Z = tH follows from H alone, the proof says nothing more about Z;
+// what it shows is that the prover knows x.
+func NewProofRelatedGenerators(hash crypto.Hash, g *Point, t, x *big.Int) (*Proof, error) {
+	m, err := PublicKey(g, t)
+	if err != nil {
+		return nil, err
+	}
+	h, err := PublicKey(g, x)
+	if err != nil {
+		return nil, err
+	}
+	z, err := PublicKey(m, x)
+	if err != nil {
+		return nil, err
+	}
+	return NewProof(hash, g, h, m, z, x)
+}
+
+// VerifyRelatedGenerators verifies a proof made by NewProofRelatedGenerators
+// with M taken to be tG, whatever M the proof carries.
+func (pr *Proof) VerifyRelatedGenerators(t *big.Int) bool {
+	if pr.G == nil {
+		return false
+	}
+	m, err := PublicKey(pr.G, t)
+	if err != nil {
+		return false
+	}
+	bound := *pr
+	bound.M = m
+	return bound.Verify()
+}
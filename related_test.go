@@ -0,0 +1,43 @@
+package dleq
+
+import (
+	"crypto"
+	"crypto/elliptic"
+	"math/big"
+	"testing"
+)
+
+func TestProofRelatedGenerators(t *testing.T) {
+	curve := elliptic.P256()
+	G, _, _, _, x := testStatement(t, curve)
+	_, _, _, _, tweak := testStatement(t, curve)
+
+	proof, err := NewProofRelatedGenerators(crypto.SHA256, G, tweak, x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !proof.VerifyRelatedGenerators(tweak) {
+		t.Fatal("proof was invalid with its own t")
+	}
+	if !proof.M.equal(mustPublicKey(t, G, tweak)) || !proof.Z.equal(mustPublicKey(t, proof.H, tweak)) {
+		t.Fatal("proof has the wrong M or Z")
+	}
+	wrong := new(big.Int).Add(tweak, big.NewInt(1))
+	if proof.VerifyRelatedGenerators(wrong) {
+		t.Fatal("proof verified with the wrong t")
+	}
+
+	// M comes from t, not from the proof.
+	substituted := *proof
+	substituted.M = mustPublicKey(t, G, wrong)
+	if !substituted.VerifyRelatedGenerators(tweak) {
+		t.Fatal("verification used the proof's M")
+	}
+
+	if _, err := NewProofRelatedGenerators(crypto.SHA256, G, new(big.Int), x); err != ErrScalarRange {
+		t.Fatalf("t = 0: expected ErrScalarRange, got %v", err)
+	}
+	if proof.VerifyRelatedGenerators(curve.Params().N) {
+		t.Fatal("verified with t = q")
+	}
+}
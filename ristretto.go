@@ -0,0 +1,219 @@
+package dleq
+
+import (
+	"crypto/subtle"
+	"math/big"
+)
+
+// ristretto255 is the prime-order group built on top of the edwards25519
+// curve -x^2 + y^2 = 1 + dx^2y^2 over GF(2^255 - 19), as specified in RFC
+// 9496. Each group element is a class of curve points differing by a small
+// torsion point; it has a unique 32-byte encoding.
+//
+// This backend does its arithmetic with math/big on affine coordinates. It is
+// neither fast nor constant time, and is meant for interoperating with
+// protocols standardized on ristretto255 rather than for high-volume proving.
+var (
+	ristrettoP = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 255), big.NewInt(19))
+	ristrettoL = mustInt("7237005577332262213973186563042994240857116359379907606001950938285454250989")
+
+	// d = -121665/121666
+	edwardsD = feMul(feNeg(big.NewInt(121665)), feInv(big.NewInt(121666)))
+
+	// sqrtM1 is the nonnegative square root of -1.
+	sqrtM1 = new(big.Int).Exp(big.NewInt(2), new(big.Int).Rsh(new(big.Int).Sub(ristrettoP, big.NewInt(1)), 2), ristrettoP)
+
+	// invsqrtAMinusD is 1/sqrt(a - d) for a = -1.
+	_, invsqrtAMinusD = sqrtRatioM1(big.NewInt(1), feSub(big.NewInt(-1), edwardsD))
+
+	// The edwards25519 base point has y = 4/5 and even x.
+	ristrettoBase = func() *RistrettoElement {
+		y := feMul(big.NewInt(4), feInv(big.NewInt(5)))
+		yy := feMul(y, y)
+		_, x := sqrtRatioM1(feSub(yy, big.NewInt(1)), feAdd(feMul(edwardsD, yy), big.NewInt(1)))
+		return &RistrettoElement{x: x, y: y}
+	}()
+)
+
+func mustInt(s string) *big.Int {
+	k, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		panic("dleq: bad constant " + s)
+	}
+	return k
+}
+
+func feAdd(a, b *big.Int) *big.Int { return new(big.Int).Mod(new(big.Int).Add(a, b), ristrettoP) }
+func feSub(a, b *big.Int) *big.Int { return new(big.Int).Mod(new(big.Int).Sub(a, b), ristrettoP) }
+func feMul(a, b *big.Int) *big.Int { return new(big.Int).Mod(new(big.Int).Mul(a, b), ristrettoP) }
+func feNeg(a *big.Int) *big.Int    { return new(big.Int).Mod(new(big.Int).Neg(a), ristrettoP) }
+func feInv(a *big.Int) *big.Int    { return new(big.Int).ModInverse(a, ristrettoP) }
+
+// feIsNegative reports whether a is negative in the RFC 9496 sense, that is
+// whether its canonical encoding has the low bit set.
+func feIsNegative(a *big.Int) bool {
+	return new(big.Int).Mod(a, ristrettoP).Bit(0) == 1
+}
+
+func feAbs(a *big.Int) *big.Int {
+	if feIsNegative(a) {
+		return feNeg(a)
+	}
+	return new(big.Int).Mod(a, ristrettoP)
+}
+
+// sqrtRatioM1 computes the nonnegative square root of u/v if it exists, and
+// otherwise the nonnegative root of sqrt(-1)*u/v. It reports which.
+func sqrtRatioM1(u, v *big.Int) (bool, *big.Int) {
+	v3 := feMul(feMul(v, v), v)
+	v7 := feMul(feMul(v3, v3), v)
+	exp := new(big.Int).Rsh(new(big.Int).Sub(ristrettoP, big.NewInt(5)), 3)
+	r := feMul(feMul(u, v3), new(big.Int).Exp(feMul(u, v7), exp, ristrettoP))
+
+	check := feMul(v, feMul(r, r))
+	u = new(big.Int).Mod(u, ristrettoP)
+	correctSign := check.Cmp(u) == 0
+	flippedSign := check.Cmp(feNeg(u)) == 0
+	flippedSignI := check.Cmp(feMul(feNeg(u), sqrtM1)) == 0
+	if flippedSign || flippedSignI {
+		r = feMul(r, sqrtM1)
+	}
+	return correctSign || flippedSign, feAbs(r)
+}
+
+// A RistrettoElement is an element of the ristretto255 group. The zero value
+// is not valid; elements come from Ristretto255.Generator, DecodeRistretto,
+// or arithmetic on those.
+type RistrettoElement struct {
+	x, y *big.Int // affine coordinates of one point in the class
+}
+
+var _ GroupElement = (*RistrettoElement)(nil)
+
+// DecodeRistretto decodes a canonical 32-byte ristretto255 encoding.
+func DecodeRistretto(data []byte) (*RistrettoElement, error) {
+	if len(data) != 32 {
+		return nil, ErrInvalidPoint
+	}
+	le := append([]byte{}, data...)
+	reverse(le)
+	s := new(big.Int).SetBytes(le)
+	if s.Cmp(ristrettoP) >= 0 || feIsNegative(s) {
+		return nil, ErrNonCanonicalEncoding
+	}
+
+	one := big.NewInt(1)
+	ss := feMul(s, s)
+	u1 := feSub(one, ss)
+	u2 := feAdd(one, ss)
+	u2Sqr := feMul(u2, u2)
+	v := feSub(feNeg(feMul(edwardsD, feMul(u1, u1))), u2Sqr)
+	wasSquare, invsqrt := sqrtRatioM1(one, feMul(v, u2Sqr))
+	denX := feMul(invsqrt, u2)
+	denY := feMul(feMul(invsqrt, denX), v)
+	x := feAbs(feMul(feMul(big.NewInt(2), s), denX))
+	y := feMul(u1, denY)
+	if !wasSquare || feIsNegative(feMul(x, y)) || y.Sign() == 0 {
+		return nil, ErrInvalidPoint
+	}
+	return &RistrettoElement{x: x, y: y}, nil
+}
+
+// Marshal returns the canonical 32-byte encoding of the element.
+func (e *RistrettoElement) Marshal() []byte {
+	x0, y0 := e.x, e.y
+	t0 := feMul(x0, y0)
+	one := big.NewInt(1)
+
+	u1 := feMul(feAdd(one, y0), feSub(one, y0))
+	u2 := feMul(x0, y0)
+	_, invsqrt := sqrtRatioM1(one, feMul(u1, feMul(u2, u2)))
+	den1 := feMul(invsqrt, u1)
+	den2 := feMul(invsqrt, u2)
+	zInv := feMul(feMul(den1, den2), t0)
+
+	x, y, denInv := x0, y0, den2
+	if feIsNegative(feMul(t0, zInv)) {
+		x, y = feMul(y0, sqrtM1), feMul(x0, sqrtM1)
+		denInv = feMul(den1, invsqrtAMinusD)
+	}
+	if feIsNegative(feMul(x, zInv)) {
+		y = feNeg(y)
+	}
+	s := feAbs(feMul(denInv, feSub(one, y)))
+
+	out := make([]byte, 32)
+	s.FillBytes(out)
+	reverse(out)
+	return out
+}
+
+func (e *RistrettoElement) add(q *RistrettoElement) *RistrettoElement {
+	// The twisted Edwards addition law, complete since a = -1 is a square
+	// and d isn't:
+	// x3 = (x1y2 + y1x2) / (1 + dx1x2y1y2)
+	// y3 = (y1y2 + x1x2) / (1 - dx1x2y1y2)
+	one := big.NewInt(1)
+	xx := feMul(e.x, q.x)
+	yy := feMul(e.y, q.y)
+	dxy := feMul(edwardsD, feMul(xx, yy))
+	x := feMul(feAdd(feMul(e.x, q.y), feMul(e.y, q.x)), feInv(feAdd(one, dxy)))
+	y := feMul(feAdd(yy, xx), feInv(feSub(one, dxy)))
+	return &RistrettoElement{x: x, y: y}
+}
+
+func (e *RistrettoElement) asRistretto(g GroupElement) *RistrettoElement {
+	q, ok := g.(*RistrettoElement)
+	if !ok || q == nil {
+		return nil
+	}
+	return q
+}
+
+// Add returns e + g. It panics if g is not a ristretto255 element.
+func (e *RistrettoElement) Add(g GroupElement) GroupElement {
+	q := e.asRistretto(g)
+	if q == nil {
+		panic("dleq: adding elements of different groups")
+	}
+	return e.add(q)
+}
+
+// ScalarMul returns ke, with k reduced mod the group order.
+func (e *RistrettoElement) ScalarMul(k *big.Int) GroupElement {
+	k = new(big.Int).Mod(k, ristrettoL)
+	acc := &RistrettoElement{x: new(big.Int), y: big.NewInt(1)}
+	for i := k.BitLen() - 1; i >= 0; i-- {
+		acc = acc.add(acc)
+		if k.Bit(i) == 1 {
+			acc = acc.add(e)
+		}
+	}
+	return acc
+}
+
+// Equal compares the encodings of e and g, since the same element has
+// several representations as curve points.
+func (e *RistrettoElement) Equal(g GroupElement) bool {
+	q := e.asRistretto(g)
+	return q != nil && subtle.ConstantTimeCompare(e.Marshal(), q.Marshal()) == 1
+}
+
+func (e *RistrettoElement) IsIdentity() bool {
+	return subtle.ConstantTimeCompare(e.Marshal(), make([]byte, 32)) == 1
+}
+
+type ristrettoGroup struct{}
+
+// Ristretto255 is the ristretto255 group, for proofs made with
+// NewGroupProof.
+var Ristretto255 Group = ristrettoGroup{}
+
+func (ristrettoGroup) Order() *big.Int { return new(big.Int).Set(ristrettoL) }
+
+func (ristrettoGroup) Generator() GroupElement { return ristrettoBase }
+
+func (ristrettoGroup) Contains(e GroupElement) bool {
+	q, ok := e.(*RistrettoElement)
+	return ok && q != nil && q.x != nil && q.y != nil
+}
@@ -0,0 +1,133 @@
+package dleq
+
+import (
+	"crypto"
+	"crypto/rand"
+	"encoding/hex"
+	"math/big"
+	"testing"
+)
+
+func TestRistrettoBaseEncoding(t *testing.T) {
+	// RFC 9496, Appendix A.1: the identity and the generator.
+	identity := Ristretto255.Generator().ScalarMul(new(big.Int))
+	if got := hex.EncodeToString(identity.Marshal()); got != "0000000000000000000000000000000000000000000000000000000000000000" {
+		t.Errorf("identity encoded as %s", got)
+	}
+	const base = "e2f2ae0a6abc4e71a884a961c500515f58e30b6aa582dd8db6a65945e08d2d76"
+	if got := hex.EncodeToString(Ristretto255.Generator().Marshal()); got != base {
+		t.Errorf("generator encoded as %s", got)
+	}
+}
+
+func TestRistrettoRoundTrip(t *testing.T) {
+	B := Ristretto255.Generator()
+	for i := int64(0); i < 16; i++ {
+		e := B.ScalarMul(big.NewInt(i))
+		data := e.Marshal()
+		decoded, err := DecodeRistretto(data)
+		if err != nil {
+			t.Fatalf("%dB: %v", i, err)
+		}
+		if !decoded.Equal(e) {
+			t.Fatalf("%dB did not round trip", i)
+		}
+	}
+
+	// The encoding is of the class, not the representative: adding a
+	// 2-torsion point changes the point but not the element.
+	torsion := &RistrettoElement{x: new(big.Int), y: feNeg(big.NewInt(1))}
+	e := B.ScalarMul(big.NewInt(5)).(*RistrettoElement)
+	if !e.add(torsion).Equal(e) {
+		t.Fatal("torsion changed the element")
+	}
+}
+
+func TestRistrettoGroupLaw(t *testing.T) {
+	B := Ristretto255.Generator()
+	two := B.ScalarMul(big.NewInt(2))
+	if !B.Add(B).Equal(two) {
+		t.Error("B + B != 2B")
+	}
+	if !two.Add(B).Equal(B.ScalarMul(big.NewInt(3))) {
+		t.Error("2B + B != 3B")
+	}
+	if !B.ScalarMul(Ristretto255.Order()).IsIdentity() {
+		t.Error("lB is not the identity")
+	}
+	if B.IsIdentity() {
+		t.Error("B is the identity")
+	}
+}
+
+func TestRistrettoRejectsNonCanonical(t *testing.T) {
+	// s >= p, and a negative (odd) s.
+	p := make([]byte, 32)
+	ristrettoP.FillBytes(p)
+	reverse(p)
+	if _, err := DecodeRistretto(p); err != ErrNonCanonicalEncoding {
+		t.Errorf("s = p: expected ErrNonCanonicalEncoding, got %v", err)
+	}
+	odd := Ristretto255.Generator().Marshal()
+	odd[0] |= 1
+	if _, err := DecodeRistretto(odd); err != ErrNonCanonicalEncoding {
+		t.Errorf("odd s: expected ErrNonCanonicalEncoding, got %v", err)
+	}
+	if _, err := DecodeRistretto(make([]byte, 31)); err != ErrInvalidPoint {
+		t.Errorf("short: expected ErrInvalidPoint, got %v", err)
+	}
+}
+
+func testRistrettoStatement(t *testing.T) (G, H, M, Z GroupElement, x *big.Int) {
+	N := Ristretto255.Order()
+	var scalars [3]*big.Int
+	for i := range scalars {
+		k, err := rand.Int(rand.Reader, N)
+		if err != nil {
+			t.Fatal(err)
+		}
+		scalars[i] = k
+	}
+	B := Ristretto255.Generator()
+	G, M, x = B.ScalarMul(scalars[0]), B.ScalarMul(scalars[1]), scalars[2]
+	return G, G.ScalarMul(x), M, M.ScalarMul(x), x
+}
+
+func TestRistrettoProof(t *testing.T) {
+	G, H, M, Z, x := testRistrettoStatement(t)
+	proof, err := NewGroupProof(crypto.SHA512, Ristretto255, G, H, M, Z, x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !proof.Verify() {
+		t.Fatal("proof was invalid")
+	}
+
+	wrong := *proof
+	wrong.Z = Z.Add(M)
+	if wrong.Verify() {
+		t.Fatal("verified a false statement")
+	}
+}
+
+func TestRistrettoTranscriptEncoding(t *testing.T) {
+	// Decoding the elements back from their canonical encodings gives
+	// different representatives of the same elements, which must not
+	// change the transcript.
+	G, H, M, Z, x := testRistrettoStatement(t)
+	proof, err := NewGroupProof(crypto.SHA512, Ristretto255, G, H, M, Z, x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded := *proof
+	for _, e := range []*GroupElement{&decoded.G, &decoded.H, &decoded.M, &decoded.Z} {
+		d, err := DecodeRistretto((*e).Marshal())
+		if err != nil {
+			t.Fatal(err)
+		}
+		*e = d
+	}
+	if !decoded.Verify() {
+		t.Fatal("proof was invalid over decoded elements")
+	}
+}
@@ -0,0 +1,36 @@
+package dleq
+
+import (
+	"crypto"
+	crand "crypto/rand"
+	"io"
+	"math/big"
+)
+
+// SaltSize is the length of the salt of a salted proof.
+const SaltSize = 32
+
+// NewProofWithSalt proves statement with a fresh random salt folded into
+// the transcript, at the cost of SaltSize bytes on the wire. Soundness is
+// unchanged, since the salt is public and bound like any other transcript
+// field.
+//
+// The salt is no protection against a failing nonce source. If the nonce
+// repeats, a fresh salt gives a new challenge over the same commitments
+// (a, b), and two such proofs reveal the witness to ExtractWitness. The
+// repeated commitments also link the proofs regardless of the salt.
+//
+// Unlike a context, the salt is part of the proof: it is serialized, and
+// Verify uses it without being told.
+func NewProofWithSalt(hash crypto.Hash, statement *Statement, x *big.Int) (*Proof, error) {
+	salt := make([]byte, SaltSize)
+	if _, err := io.ReadFull(crand.Reader, salt); err != nil {
+		return nil, randomnessError(err)
+	}
+	proof := newProof(hash, statement, nil)
+	proof.salt = salt
+	if _, _, err := proof.prove(x, crand.Reader); err != nil {
+		return nil, err
+	}
+	return proof, nil
+}
@@ -0,0 +1,78 @@
+package dleq
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/elliptic"
+	"encoding/json"
+	"testing"
+)
+
+func TestNewProofWithSalt(t *testing.T) {
+	G, H, M, Z, x := testStatement(t, elliptic.P256())
+	stmt := &Statement{G: G, H: H, M: M, Z: Z}
+	p1, err := NewProofWithSalt(crypto.SHA256, stmt, x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p2, err := NewProofWithSalt(crypto.SHA256, stmt, x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !p1.Verify() || !p2.Verify() {
+		t.Fatal("salted proof was invalid")
+	}
+	d1, err := p1.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	d2, err := p2.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(d1, d2) || bytes.Equal(p1.salt, p2.salt) {
+		t.Fatal("two salted proofs of one statement were the same")
+	}
+	if p1.Size() != len(d1) {
+		t.Fatalf("Size is %d, encoding is %d bytes", p1.Size(), len(d1))
+	}
+
+	// The salt is part of the transcript.
+	unsalted := *p1
+	unsalted.salt = nil
+	if unsalted.Verify() {
+		t.Fatal("salted proof verified without its salt")
+	}
+
+	// Every encoding carries it.
+	fromBinary := new(Proof)
+	if err := fromBinary.UnmarshalBinary(d1); err != nil {
+		t.Fatal(err)
+	}
+	fromStream := new(Proof)
+	if _, err := fromStream.ReadFrom(bytes.NewReader(d1)); err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(p1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fromJSON := new(Proof)
+	if err := json.Unmarshal(data, fromJSON); err != nil {
+		t.Fatal(err)
+	}
+	for _, decoded := range []*Proof{fromBinary, fromStream, fromJSON} {
+		if !bytes.Equal(decoded.salt, p1.salt) || !decoded.Verify() {
+			t.Fatal("round trip lost the salt")
+		}
+	}
+
+	// An extension byte with no flags set is not canonical.
+	bad := append([]byte{}, d1[:binaryHeaderSize]...)
+	bad[3] |= flagExtended
+	bad = append(bad, 0)
+	bad = append(bad, d1[binaryHeaderSize+1:]...)
+	if err := new(Proof).UnmarshalBinary(bad); err != ErrMalformedProof {
+		t.Fatalf("expected ErrMalformedProof, got %v", err)
+	}
+}
@@ -0,0 +1,33 @@
+package dleq
+
+import (
+	"crypto"
+	"io"
+	"math/big"
+)
+
+// SecureRand is a randomness source that can vouch for itself, such as a
+// hardware RNG with continuous health tests. Wherever the package samples a
+// nonce from a source implementing it, HealthCheck is called first, and a
+// failure is returned, wrapped in ErrRandomnessUnavailable, instead of a
+// proof made with a nonce that might be weak.
+type SecureRand interface {
+	io.Reader
+	HealthCheck() error
+}
+
+// NewProofWithSecureRand is NewProof with its nonce from rand, which has to
+// pass HealthCheck first.
+func NewProofWithSecureRand(hash crypto.Hash, g, h, m, z *Point, x *big.Int, rand SecureRand) (*Proof, error) {
+	return NewProofWithReader(hash, g, h, m, z, x, rand)
+}
+
+// checkHealth runs the health check of rand, if it has one.
+func checkHealth(rand io.Reader) error {
+	if sr, ok := rand.(SecureRand); ok {
+		if err := sr.HealthCheck(); err != nil {
+			return randomnessError(err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package dleq
+
+import (
+	"crypto"
+	"crypto/elliptic"
+	"crypto/rand"
+	"errors"
+	"testing"
+)
+
+var errUnhealthy = errors.New("entropy source failed its self-test")
+
+// checkedRand is crypto/rand with a health check that can be failed.
+type checkedRand struct {
+	healthy bool
+	checks  int
+	reads   int
+}
+
+func (r *checkedRand) Read(p []byte) (int, error) {
+	r.reads++
+	return rand.Read(p)
+}
+
+func (r *checkedRand) HealthCheck() error {
+	r.checks++
+	if !r.healthy {
+		return errUnhealthy
+	}
+	return nil
+}
+
+func TestNewProofWithSecureRand(t *testing.T) {
+	G, H, M, Z, x := testStatement(t, elliptic.P256())
+
+	src := &checkedRand{healthy: true}
+	proof, err := NewProofWithSecureRand(crypto.SHA256, G, H, M, Z, x, src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !proof.Verify() {
+		t.Fatal("proof was invalid")
+	}
+	if src.checks != 1 || src.reads == 0 {
+		t.Fatalf("%d health checks and %d reads, expected one check before reading", src.checks, src.reads)
+	}
+
+	src = &checkedRand{}
+	proof, err = NewProofWithSecureRand(crypto.SHA256, G, H, M, Z, x, src)
+	if !errors.Is(err, errUnhealthy) || !errors.Is(err, ErrRandomnessUnavailable) || proof != nil {
+		t.Fatalf("unhealthy source: got %v, %v", proof, err)
+	}
+	if src.reads != 0 {
+		t.Fatal("read a nonce from an unhealthy source")
+	}
+
+	// A Prover checks a SecureRand it is given as well.
+	prover := NewProver(crypto.SHA256, x)
+	prover.Rand = src
+	if _, err := prover.Prove(&Statement{G: G, H: H, M: M, Z: Z}); !errors.Is(err, errUnhealthy) {
+		t.Fatalf("unhealthy source in Prover: got %v", err)
+	}
+}
+
+// failingRand is a randomness source that always fails, as crypto/rand does
+// on a platform that blocks getrandom.
+type failingRand struct{}
+
+var errNoEntropy = errors.New("getrandom: operation not permitted")
+
+func (failingRand) Read(p []byte) (int, error) {
+	return 0, errNoEntropy
+}
+
+func TestRandomnessUnavailable(t *testing.T) {
+	G, H, M, Z, x := testStatement(t, elliptic.P256())
+	stmt := &Statement{G: G, H: H, M: M, Z: Z}
+	check := func(name string, err error) {
+		t.Helper()
+		if !errors.Is(err, ErrRandomnessUnavailable) || !errors.Is(err, errNoEntropy) {
+			t.Errorf("%s: got %v, expected ErrRandomnessUnavailable", name, err)
+		}
+		if IsValidationError(err) {
+			t.Errorf("%s: a randomness failure was reported as bad input", name)
+		}
+	}
+
+	proof, err := NewProofWithReader(crypto.SHA256, G, H, M, Z, x, failingRand{})
+	if proof != nil {
+		t.Error("NewProofWithReader returned a proof")
+	}
+	check("NewProofWithReader", err)
+
+	prover := NewProver(crypto.SHA256, x)
+	prover.Rand = failingRand{}
+	_, err = prover.Prove(stmt)
+	check("Prover.Prove", err)
+	_, _, err = prover.Commit(stmt)
+	check("Prover.Commit", err)
+
+	bG, bH, k, tokens, signed := testIssuance(t, 3)
+	_, err = newBatchProof(crypto.SHA256, bG, bH, tokens, signed, k, failingRand{})
+	check("batch", err)
+
+	// A working source still proves.
+	proof, err = NewProofWithReader(crypto.SHA256, G, H, M, Z, x, rand.Reader)
+	if err != nil || !proof.Verify() {
+		t.Fatalf("with crypto/rand: %v", err)
+	}
+}
@@ -0,0 +1,29 @@
+package dleq
+
+// SecurityLevel reports the parameters a proof's security rests on, for a
+// verifier policy that wants a floor on them. soundnessBits is the length
+// of the challenge: a prover that doesn't know the witness can cheat with
+// probability about 2^-soundnessBits. It is the smallest of the bit length
+// of the group order, the hash output behind the challenge, and
+// ChallengeBits for a truncated challenge. groupBits is the bit length of
+// the group order, which bounds how hard the discrete logs are, and so the
+// zero-knowledge and the binding of the commitments, at about groupBits/2.
+//
+// Both are zero for a proof without a curve or a usable hash.
+func (pr *Proof) SecurityLevel() (soundnessBits int, groupBits int) {
+	if pr.G == nil || pr.G.Curve == nil || !validHash(pr.hash) || (pr.hash2 != 0 && !validHash(pr.hash2)) {
+		return 0, 0
+	}
+	groupBits = pr.G.Curve.Params().N.BitLen()
+	soundnessBits = 8 * pr.hash.Size()
+	if pr.hash2 != 0 {
+		soundnessBits += 8 * pr.hash2.Size()
+	}
+	if groupBits < soundnessBits {
+		soundnessBits = groupBits
+	}
+	if k := pr.opts.ChallengeBits; k != 0 && k < soundnessBits {
+		soundnessBits = k
+	}
+	return soundnessBits, groupBits
+}
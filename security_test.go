@@ -0,0 +1,51 @@
+package dleq
+
+import (
+	"crypto"
+	"crypto/elliptic"
+	"testing"
+)
+
+func TestSecurityLevel(t *testing.T) {
+	for _, tc := range []struct {
+		curve     elliptic.Curve
+		hash      crypto.Hash
+		opts      *Options
+		soundness int
+		group     int
+	}{
+		{elliptic.P256(), crypto.SHA256, nil, 256, 256},
+		{elliptic.P256(), crypto.SHA512, nil, 256, 256},
+		{elliptic.P384(), crypto.SHA256, nil, 256, 384},
+		{elliptic.P384(), crypto.SHA384, nil, 384, 384},
+		{elliptic.P521(), crypto.SHA512, nil, 512, 521},
+		{elliptic.P224(), crypto.SHA256, nil, 224, 224},
+		{elliptic.P256(), crypto.SHA256, &Options{ChallengeBits: 128}, 128, 256},
+		{elliptic.P521(), crypto.SHA512, &Options{ChallengeBits: MinChallengeBits}, MinChallengeBits, 521},
+	} {
+		G, H, M, Z, x := testStatement(t, tc.curve)
+		proof, err := NewProofWithOptions(tc.hash, G, H, M, Z, x, tc.opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		soundness, group := proof.SecurityLevel()
+		if soundness != tc.soundness || group != tc.group {
+			t.Errorf("%s with %v, %+v: got (%d, %d), expected (%d, %d)",
+				tc.curve.Params().Name, tc.hash, tc.opts, soundness, group, tc.soundness, tc.group)
+		}
+	}
+
+	// Both transcript hashes of a hybrid proof count towards the challenge.
+	G, H, M, Z, x := testStatement(t, elliptic.P521())
+	hybrid, err := NewHybridProof(crypto.SHA256, crypto.SHA256, G, H, M, Z, x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if soundness, _ := hybrid.SecurityLevel(); soundness != 512 {
+		t.Errorf("hybrid SHA-256 proof on P-521: got %d soundness bits, expected 512", soundness)
+	}
+
+	if soundness, group := new(Proof).SecurityLevel(); soundness != 0 || group != 0 {
+		t.Errorf("empty proof: got (%d, %d), expected zeros", soundness, group)
+	}
+}
@@ -0,0 +1,31 @@
+package dleq
+
+import (
+	"crypto"
+	crand "crypto/rand"
+	"math/big"
+)
+
+// NewProofInSet proves statement with merkleRoot folded into the
+// transcript, binding the proof to a larger set of statements committed to
+// by that root. A verifier checking some subset of the statements knows the
+// proofs were made for that set; that each statement is actually a member
+// is shown separately with an ordinary Merkle path.
+//
+// The root is not part of the serialized forms. Verifiers supply it to
+// VerifyInSet.
+func NewProofInSet(hash crypto.Hash, statement *Statement, x *big.Int, merkleRoot []byte) (*Proof, error) {
+	proof := newProof(hash, statement, nil)
+	proof.root = append([]byte{}, merkleRoot...)
+	if _, _, err := proof.prove(x, crand.Reader); err != nil {
+		return nil, err
+	}
+	return proof, nil
+}
+
+// VerifyInSet verifies a proof made by NewProofInSet against the given root.
+func (pr *Proof) VerifyInSet(merkleRoot []byte) bool {
+	bound := *pr
+	bound.root = append([]byte{}, merkleRoot...)
+	return bound.Verify()
+}
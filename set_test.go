@@ -0,0 +1,34 @@
+package dleq
+
+import (
+	"crypto"
+	"crypto/elliptic"
+	"crypto/sha256"
+	"testing"
+)
+
+func TestProofInSet(t *testing.T) {
+	G, H, M, Z, x := testStatement(t, elliptic.P256())
+	rootA := sha256.Sum256([]byte("set A"))
+	rootB := sha256.Sum256([]byte("set B"))
+
+	proof, err := NewProofInSet(crypto.SHA256, &Statement{G: G, H: H, M: M, Z: Z}, x, rootA[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !proof.VerifyInSet(rootA[:]) {
+		t.Fatal("proof was invalid under its own root")
+	}
+	if proof.VerifyInSet(rootB[:]) {
+		t.Fatal("proof verified under a different root")
+	}
+
+	// A plain proof isn't bound to any set.
+	plain, err := NewProof(crypto.SHA256, G, H, M, Z, x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plain.VerifyInSet(rootA[:]) {
+		t.Fatal("unbound proof verified as a member of a set")
+	}
+}
@@ -0,0 +1,103 @@
+package dleq
+
+import (
+	"crypto"
+	crand "crypto/rand"
+	"math/big"
+)
+
+// A SharedChallengeProof proves several independent statements, each with
+// its own witness xi, under one challenge: log_Gi(Hi) == log_Mi(Zi) == xi
+// for every i. Each statement gets its own nonce and response, so the proof
+// is one C and a response Ri per statement, against a C and an R for each
+// of separate proofs. Unlike a ConjunctiveProof it says nothing about the
+// witnesses being equal.
+//
+// The transcript is that of ConjunctiveProof: all of the statements' points
+// in turn, then all of the commitments.
+type SharedChallengeProof struct {
+	Statements []*Statement
+	C          *big.Int
+	R          []*big.Int
+
+	params
+}
+
+// NewSharedChallengeProof proves each of statements with the witness of the
+// same index in xs. It fails with ErrCountMismatch if there aren't as many
+// witnesses as statements.
+func NewSharedChallengeProof(hash crypto.Hash, statements []*Statement, xs []*big.Int) (*SharedChallengeProof, error) {
+	if len(statements) == 0 {
+		return nil, ErrIncompleteProof
+	}
+	if len(xs) != len(statements) {
+		return nil, ErrCountMismatch
+	}
+	if err := checkConjunction(statements); err != nil {
+		return nil, err
+	}
+	if !hash.Available() {
+		return nil, ErrUnknownHash
+	}
+	curve := statements[0].G.Curve
+
+	nonces := make([]*big.Int, len(statements))
+	var commitments []*Point
+	for i, stmt := range statements {
+		s, a, b, err := commit(curve, stmt.G, stmt.M, crand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		nonces[i] = s
+		commitments = append(commitments, a, b)
+	}
+
+	sp := &SharedChallengeProof{
+		Statements: append([]*Statement{}, statements...),
+		params:     params{hash: hash},
+	}
+	sp.C = statementsChallenge(hash, sp.Statements, commitments)
+	sp.R = make([]*big.Int, len(statements))
+	for i, s := range nonces {
+		sp.R[i] = ComputeResponse(s, sp.C, xs[i], curve.Params().N)
+	}
+	return sp, nil
+}
+
+func (sp *SharedChallengeProof) Verify() bool {
+	return sp.VerifyError() == nil
+}
+
+// VerifyError is Verify reporting why the proof was rejected, as
+// Proof.VerifyError does.
+func (sp *SharedChallengeProof) VerifyError() error {
+	if len(sp.Statements) == 0 || sp.C == nil {
+		return ErrIncompleteProof
+	}
+	if len(sp.R) != len(sp.Statements) {
+		return ErrCountMismatch
+	}
+	if err := checkConjunction(sp.Statements); err != nil {
+		return err
+	}
+	if !sp.hash.Available() {
+		return ErrUnknownHash
+	}
+
+	var commitments []*Point
+	for i, stmt := range sp.Statements {
+		if sp.R[i] == nil {
+			return ErrIncompleteProof
+		}
+		pr := &Proof{G: stmt.G, H: stmt.H, M: stmt.M, Z: stmt.Z, C: sp.C, R: sp.R[i], params: sp.params}
+		if err := pr.checkScalars(); err != nil {
+			return err
+		}
+		a, b := pr.commitments()
+		commitments = append(commitments, a, b)
+	}
+	if !scalarEqual(sp.Statements[0].G.Curve, sp.C, statementsChallenge(sp.hash, sp.Statements, commitments)) {
+		return ErrInvalidProof
+	}
+	return nil
+}
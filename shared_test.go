@@ -0,0 +1,80 @@
+package dleq
+
+import (
+	"crypto"
+	"crypto/elliptic"
+	"math/big"
+	"testing"
+)
+
+func TestSharedChallengeProof(t *testing.T) {
+	curve := elliptic.P256()
+	var statements []*Statement
+	var xs []*big.Int
+	for i := 0; i < 3; i++ {
+		G, H, M, Z, x := testStatement(t, curve)
+		statements = append(statements, &Statement{G: G, H: H, M: M, Z: Z})
+		xs = append(xs, x)
+	}
+	sp, err := NewSharedChallengeProof(crypto.SHA256, statements, xs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sp.VerifyError(); err != nil {
+		t.Fatal(err)
+	}
+	// One challenge and three responses, against three of each.
+	if len(sp.R) != len(statements) {
+		t.Fatalf("%d responses for %d statements", len(sp.R), len(statements))
+	}
+
+	// Each response only answers its own statement.
+	swapped := *sp
+	swapped.R = []*big.Int{sp.R[1], sp.R[0], sp.R[2]}
+	if swapped.Verify() {
+		t.Fatal("verified with responses swapped")
+	}
+	reordered := *sp
+	reordered.Statements = []*Statement{statements[1], statements[0], statements[2]}
+	reordered.R = swapped.R
+	if reordered.Verify() {
+		t.Fatal("verified with the statements reordered")
+	}
+	bad := *sp
+	bad.R = append([]*big.Int{new(big.Int).Add(sp.R[0], big.NewInt(1))}, sp.R[1:]...)
+	if err := bad.VerifyError(); err != ErrInvalidProof {
+		t.Fatalf("broken response: expected ErrInvalidProof, got %v", err)
+	}
+
+	// The witnesses are independent, so no one of them proves the lot.
+	wrong, err := NewSharedChallengeProof(crypto.SHA256, statements, []*big.Int{xs[0], xs[0], xs[0]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if wrong.Verify() {
+		t.Fatal("verified with the wrong witnesses")
+	}
+}
+
+func TestSharedChallengeProofCounts(t *testing.T) {
+	G, H, M, Z, x := testStatement(t, elliptic.P256())
+	stmt := &Statement{G: G, H: H, M: M, Z: Z}
+	for _, xs := range [][]*big.Int{nil, {x, x}} {
+		if _, err := NewSharedChallengeProof(crypto.SHA256, []*Statement{stmt}, xs); err != ErrCountMismatch {
+			t.Errorf("%d witnesses for one statement: expected ErrCountMismatch, got %v", len(xs), err)
+		}
+	}
+	if _, err := NewSharedChallengeProof(crypto.SHA256, nil, nil); err != ErrIncompleteProof {
+		t.Errorf("no statements: expected ErrIncompleteProof, got %v", err)
+	}
+
+	sp, err := NewSharedChallengeProof(crypto.SHA256, []*Statement{stmt, stmt}, []*big.Int{x, x})
+	if err != nil {
+		t.Fatal(err)
+	}
+	short := *sp
+	short.R = sp.R[:1]
+	if err := short.VerifyError(); err != ErrCountMismatch {
+		t.Errorf("one response for two statements: expected ErrCountMismatch, got %v", err)
+	}
+}
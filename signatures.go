@@ -0,0 +1,21 @@
+package dleq
+
+import (
+	"crypto"
+	"math/big"
+)
+
+// ProveManySignatures proves, for a signing oracle, that each signature
+// S_i = k*M_i over a message point M_i was made with the key behind the
+// public key Y = k*G. All the signatures share one batch proof and a single
+// challenge.
+func ProveManySignatures(hash crypto.Hash, g, y *Point, k *big.Int, ms, signed []*Point) (*BatchProof, error) {
+	return NewBatchProof(hash, g, y, ms, signed, k)
+}
+
+// VerifyManySignatures checks a proof from ProveManySignatures against the
+// messages and signatures the verifier holds. It fails as a whole if any one
+// signature was made with a different key.
+func VerifyManySignatures(g, y *Point, ms, signed []*Point, pr *BatchProof) bool {
+	return pr != nil && pr.isAbout(g, y, ms, signed) && pr.Verify()
+}
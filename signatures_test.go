@@ -0,0 +1,31 @@
+package dleq
+
+import (
+	"crypto"
+	"testing"
+)
+
+func TestManySignatures(t *testing.T) {
+	G, Y, k, ms, signed := testIssuance(t, 8)
+	proof, err := ProveManySignatures(crypto.SHA256, G, Y, k, ms, signed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !VerifyManySignatures(G, Y, ms, signed, proof) {
+		t.Fatal("proof was invalid")
+	}
+}
+
+func TestManySignaturesOneTampered(t *testing.T) {
+	// Seven honest signatures and one that is off by a message point.
+	G, Y, k, ms, signed := testIssuance(t, 8)
+	signed[5] = signed[5].Add(ms[5]).(*Point)
+
+	proof, err := ProveManySignatures(crypto.SHA256, G, Y, k, ms, signed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if VerifyManySignatures(G, Y, ms, signed, proof) {
+		t.Fatal("verified a batch with a tampered signature")
+	}
+}
@@ -0,0 +1,153 @@
+package dleq
+
+import (
+	"encoding/binary"
+	"io"
+)
+
+// ReadFrom reads one proof in the binary format from r, consuming exactly
+// its bytes, and decodes it within the default DecoderOptions. It returns
+// the number of bytes read. A stream ending before the first byte gives
+// io.EOF and one ending inside the proof io.ErrUnexpectedEOF.
+//
+// Like Point.ReadFrom, it stops after one proof rather than reading r to
+// the end, so proofs written back to back with AppendTo can be read off one
+// at a time. r is read in small pieces; wrap it in a bufio.Reader if that
+// matters.
+func (pr *Proof) ReadFrom(r io.Reader) (int64, error) {
+	return pr.ReadFromOptions(r, nil)
+}
+
+// ReadFromOptions is ReadFrom within the given decoder limits, which also
+// bound how much it reads before giving up with ErrProofTooLarge.
+func (pr *Proof) ReadFromOptions(r io.Reader, limits *DecoderOptions) (int64, error) {
+	buf := make([]byte, binaryHeaderSize)
+	if n, err := io.ReadFull(r, buf); err != nil {
+		return int64(n), err
+	}
+	// read appends the next k bytes of the proof to buf.
+	read := func(k int) error {
+		if len(buf)+k > limits.maxProofSize() {
+			return ErrProofTooLarge
+		}
+		start := len(buf)
+		buf = append(buf, make([]byte, k)...)
+		n, err := io.ReadFull(r, buf[start:])
+		buf = buf[:start+n]
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return err
+	}
+
+	// Only as much of the header is interpreted as it takes to find the
+	// end of the proof; UnmarshalBinaryOptions checks the rest.
+	flags := buf[3]
+	if buf[0] != binaryVersion || flags&^knownFlags != 0 {
+		return int64(len(buf)), ErrMalformedProof
+	}
+	curve, ok := curveByID(buf[1])
+	if !ok {
+		return int64(len(buf)), ErrUnknownCurve
+	}
+	var ext byte
+	if flags&flagExtended != 0 {
+		if err := read(1); err != nil {
+			return int64(len(buf)), err
+		}
+		ext = buf[len(buf)-1]
+	}
+	var opts Options
+	if flags&flagTruncated != 0 {
+		if err := read(2); err != nil {
+			return int64(len(buf)), err
+		}
+		opts.ChallengeBits = int(binary.BigEndian.Uint16(buf[len(buf)-2:]))
+		if opts.ChallengeBits == 0 || opts.checkChallengeBits(curve) != nil {
+			return int64(len(buf)), ErrMalformedProof
+		}
+	}
+	var baseMask byte
+	if flags&flagBasePoints != 0 {
+		if err := read(1); err != nil {
+			return int64(len(buf)), err
+		}
+		baseMask = buf[len(buf)-1]
+	}
+	if ext&extOrder != 0 {
+		if err := read(1); err != nil {
+			return int64(len(buf)), err
+		}
+	}
+	for i := 0; i < 4; i++ {
+		if baseMask&(1<<i) != 0 {
+			continue
+		}
+		if err := read(2); err != nil {
+			return int64(len(buf)), err
+		}
+		size := PointEncoding(buf[len(buf)-2]).prefixLen(curve, buf[len(buf)-1])
+		if size == 0 {
+			return int64(len(buf)), ErrUnknownEncoding
+		}
+		if err := read(size - 1); err != nil {
+			return int64(len(buf)), err
+		}
+	}
+	scalarSize := ScalarLen(curve)
+	if err := read(opts.challengeSize(curve) + scalarSize); err != nil {
+		return int64(len(buf)), err
+	}
+	if flags&flagBeacon != 0 {
+		if err := read(8); err != nil {
+			return int64(len(buf)), err
+		}
+		start := len(buf)
+		for len(buf)-start < binary.MaxVarintLen64 && (len(buf) == start || buf[len(buf)-1] >= 0x80) {
+			if err := read(1); err != nil {
+				return int64(len(buf)), err
+			}
+		}
+		length, n := binary.Uvarint(buf[start:])
+		if n <= 0 {
+			return int64(len(buf)), ErrMalformedProof
+		}
+		if length > uint64(limits.maxFieldSize()) {
+			return int64(len(buf)), ErrProofTooLarge
+		}
+		if err := read(int(length)); err != nil {
+			return int64(len(buf)), err
+		}
+	}
+	if flags&flagHybrid != 0 {
+		if err := read(1); err != nil {
+			return int64(len(buf)), err
+		}
+	}
+	if ext&extSalt != 0 {
+		if err := read(SaltSize); err != nil {
+			return int64(len(buf)), err
+		}
+	}
+	return int64(len(buf)), pr.UnmarshalBinaryOptions(buf, limits)
+}
+
+// VerifyStream reads proofs laid out back to back in r, as AppendTo writes
+// them, and verifies each in turn. It returns the number of proofs that
+// verified before it stopped: at the end of the stream, which is not an
+// error if it falls between proofs, or at the first proof that is
+// malformed or fails, with the reason.
+func VerifyStream(r io.Reader) (int, error) {
+	for n := 0; ; n++ {
+		var pr Proof
+		if _, err := pr.ReadFrom(r); err != nil {
+			if err == io.EOF {
+				err = nil
+			}
+			return n, err
+		}
+		if err := pr.VerifyError(); err != nil {
+			return n, err
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package dleq
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/elliptic"
+	"io"
+	"testing"
+)
+
+func TestAppendToVerifyStream(t *testing.T) {
+	var log []byte
+	var want [][]byte
+	for i, curve := range []elliptic.Curve{elliptic.P256(), elliptic.P384(), elliptic.P521()} {
+		G, H, M, Z, x := testStatement(t, curve)
+		opts := &Options{ChallengeBits: 128 * (i % 2)}
+		proof, err := NewProofWithOptions(crypto.SHA256, G, H, M, Z, x, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if i == 2 {
+			beacon := bytes.Repeat([]byte{0xaa}, 200)
+			proof, err = NewProofWithBeacon(crypto.SHA256, &Statement{G: G, H: H, M: M, Z: Z}, x, 7, beacon)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+		data, err := proof.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		want = append(want, data)
+		if log, err = proof.AppendTo(log); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !bytes.Equal(log, bytes.Join(want, nil)) {
+		t.Fatal("AppendTo differs from MarshalBinary")
+	}
+
+	n, err := VerifyStream(bytes.NewReader(log))
+	if err != nil || n != len(want) {
+		t.Fatalf("verified %d of %d proofs: %v", n, len(want), err)
+	}
+
+	// Proofs come back one at a time, each consuming exactly its bytes.
+	r := bytes.NewReader(log)
+	for i := range want {
+		var pr Proof
+		read, err := pr.ReadFrom(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if read != int64(len(want[i])) {
+			t.Fatalf("proof %d: read %d bytes, expected %d", i, read, len(want[i]))
+		}
+	}
+	if _, err := new(Proof).ReadFrom(r); err != io.EOF {
+		t.Fatalf("expected io.EOF at the end, got %v", err)
+	}
+}
+
+func TestVerifyStreamStops(t *testing.T) {
+	G, H, M, Z, x := testStatement(t, elliptic.P256())
+	proof, err := NewProof(crypto.SHA256, G, H, M, Z, x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	log, err := proof.AppendTo(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bad := *proof
+	bad.Z = H
+	if log, err = bad.AppendTo(log); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := VerifyStream(bytes.NewReader(log)); n != 1 || err != ErrInvalidProof {
+		t.Fatalf("expected 1 proof then ErrInvalidProof, got %d, %v", n, err)
+	}
+
+	truncated := log[:len(log)-10]
+	if n, err := VerifyStream(bytes.NewReader(truncated)); n != 1 || err != io.ErrUnexpectedEOF {
+		t.Fatalf("expected 1 proof then io.ErrUnexpectedEOF, got %d, %v", n, err)
+	}
+
+	// A failed AppendTo leaves dst alone.
+	before := len(log)
+	if log, err = new(Proof).AppendTo(log); err != ErrIncompleteProof || len(log) != before {
+		t.Fatalf("expected an unchanged slice and ErrIncompleteProof, got %d bytes, %v", len(log), err)
+	}
+}
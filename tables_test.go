@@ -0,0 +1,127 @@
+package dleq
+
+import (
+	"crypto"
+	"crypto/elliptic"
+	"crypto/rand"
+	"math/big"
+	"testing"
+)
+
+func TestFixedBaseMul(t *testing.T) {
+	for _, curve := range []elliptic.Curve{elliptic.P256(), copyCurve(elliptic.P384())} {
+		G, _, _, _, _ := testStatement(t, curve)
+		N := curve.Params().N
+		for _, window := range []int{1, 3, 4} {
+			fb := newFixedBase(G, window)
+			scalars := []*big.Int{new(big.Int), big.NewInt(1), big.NewInt(2), new(big.Int).Sub(N, big.NewInt(1))}
+			for i := 0; i < 5; i++ {
+				_, k, err := randScalar(curve, rand.Reader)
+				if err != nil {
+					t.Fatal(err)
+				}
+				scalars = append(scalars, k)
+			}
+			for _, k := range scalars {
+				kb := scalarBytes(curve, k)
+				x, y := fb.mul(kb)
+				wx, wy := curve.ScalarMult(G.X, G.Y, kb)
+				if x.Cmp(wx) != 0 || y.Cmp(wy) != 0 {
+					t.Fatalf("%s window %d: table gave the wrong multiple of %x", curve.Params().Name, window, kb)
+				}
+			}
+		}
+	}
+}
+
+func TestVerifierTables(t *testing.T) {
+	for _, curve := range []elliptic.Curve{elliptic.P256(), elliptic.P384()} {
+		G, H, M, Z, x := testStatement(t, curve)
+		vt := NewVerifierTables(G, M, 4)
+		if vt == nil {
+			t.Fatal("tables were not built")
+		}
+		valid, err := NewProof(crypto.SHA256, G, H, M, Z, x)
+		if err != nil {
+			t.Fatal(err)
+		}
+		additive, err := NewProofWithOptions(crypto.SHA256, G, H, M, Z, x, &Options{Convention: Additive})
+		if err != nil {
+			t.Fatal(err)
+		}
+		wrongZ, err := NewProof(crypto.SHA256, G, H, M, H, x)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tampered := *valid
+		tampered.R = new(big.Int).Add(valid.R, big.NewInt(1))
+
+		for i, pr := range []*Proof{valid, additive, wrongZ, &tampered} {
+			if got, want := vt.Verify(pr), pr.Verify(); got != want {
+				t.Errorf("%s proof %d: tables said %v, Verify said %v", curve.Params().Name, i, got, want)
+			}
+		}
+
+		// The tables are only good for their own generators.
+		otherG, H2, _, _, x2 := testStatement(t, curve)
+		other, err := NewProof(crypto.SHA256, otherG, H2, M, mustPublicKey(t, M, x2), x2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !other.Verify() || vt.Verify(other) {
+			t.Errorf("%s: tables verified a proof over other generators", curve.Params().Name)
+		}
+	}
+
+	// The curve's base point goes through ScalarBaseMult.
+	curve := elliptic.P256()
+	_, _, M, _, x := testStatement(t, curve)
+	G := basePoint(curve)
+	proof, err := NewProof(crypto.SHA256, G, mustPublicKey(t, G, x), M, mustPublicKey(t, M, x), x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	vt := NewVerifierTables(G, M, 4)
+	if !vt.g.base || !vt.Verify(proof) {
+		t.Fatal("proof over the base point was rejected")
+	}
+
+	if NewVerifierTables(G, M, 0) != nil || NewVerifierTables(G, M, 9) != nil {
+		t.Fatal("built tables with an invalid window")
+	}
+	other, _, _, _, _ := testStatement(t, elliptic.P384())
+	if NewVerifierTables(G, other, 4) != nil {
+		t.Fatal("built tables over mixed curves")
+	}
+	if (*VerifierTables)(nil).Verify(proof) {
+		t.Fatal("nil tables verified a proof")
+	}
+}
+
+func BenchmarkVerifierTables(b *testing.B) {
+	for _, curve := range curves[1:] {
+		G, H, M, Z, x := testStatement(b, curve)
+		proof, err := NewProof(crypto.SHA256, G, H, M, Z, x)
+		if err != nil {
+			b.Fatal(err)
+		}
+		name := curve.Params().Name
+		b.Run(name+"/generic", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if !proof.Verify() {
+					b.Fatal("proof was invalid")
+				}
+			}
+		})
+		vt := NewVerifierTables(G, M, 6)
+		b.Run(name+"/tables", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if !vt.Verify(proof) {
+					b.Fatal("proof was invalid")
+				}
+			}
+		})
+	}
+}
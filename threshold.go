@@ -0,0 +1,143 @@
+package dleq
+
+import (
+	"crypto"
+	"math/big"
+)
+
+// Threshold proofs let parties holding additive shares x = x_1 + ... + x_n
+// of a witness prove the joint statement h = g^x, z = m^x without any of
+// them learning x. Each party i has its share statement h_i = g^x_i,
+// z_i = m^x_i, with h and z the sums of those. The protocol is the usual
+// one with nonce shares, and reuses the interactive Prover:
+//
+//  1. Each party calls Commit on the joint statement with a Prover holding
+//     its share, and publishes the commitment, keeping the state.
+//  2. Anyone computes JointChallenge over all the commitments.
+//  3. Each party calls Finalize with the challenge and publishes a
+//     PartialProof of its share statement, commitment and response.
+//  4. Anyone calls CombinePartialProofs to get an ordinary Proof.
+//
+// Since s = s_1 + ... + s_n and r_i = s_i - c x_i, the sum of the responses
+// is s - cx. A party that sees the others' commitments before committing
+// can bias the joint nonce, so parties that don't trust each other should
+// first exchange hashes of their commitments.
+
+// A PartialProof is one party's contribution to a threshold proof.
+type PartialProof struct {
+	H, Z       *Point // the party's share statement, with the joint G and M
+	Commitment *Commitment
+	R          *big.Int
+}
+
+// jointCommitment adds up the parties' commitments.
+func jointCommitment(stmt *Statement, commitments []*Commitment) (*Point, *Point, error) {
+	if len(commitments) == 0 {
+		return nil, nil, ErrIncompleteProof
+	}
+	curve := stmt.G.Curve
+	a := &Point{Curve: curve, X: new(big.Int), Y: new(big.Int)}
+	b := &Point{Curve: curve, X: new(big.Int), Y: new(big.Int)}
+	for _, cm := range commitments {
+		if cm == nil || cm.A == nil || cm.B == nil {
+			return nil, nil, ErrIncompleteProof
+		}
+		if !SameCurve(cm.A.Curve, curve) || !SameCurve(cm.B.Curve, curve) {
+			return nil, nil, ErrInconsistentCurves
+		}
+		if !cm.A.IsOnCurve() || !cm.B.IsOnCurve() {
+			return nil, nil, ErrPointOffCurve
+		}
+		a = a.Add(cm.A).(*Point)
+		b = b.Add(cm.B).(*Point)
+	}
+	return a, b, nil
+}
+
+// JointChallenge computes the challenge for the joint statement stmt from
+// every party's commitment, in any order.
+func JointChallenge(hash crypto.Hash, stmt *Statement, commitments []*Commitment) (*big.Int, error) {
+	if err := stmt.check(); err != nil {
+		return nil, err
+	}
+	if !hash.Available() {
+		return nil, ErrUnknownHash
+	}
+	a, b, err := jointCommitment(stmt, commitments)
+	if err != nil {
+		return nil, err
+	}
+	return newProof(hash, stmt, nil).challenge(a, b), nil
+}
+
+// CombinePartialProofs checks each party's partial proof against its share
+// statement and adds them up into a proof of stmt. It returns
+// ErrShareMismatch if the shares don't sum to stmt, and ErrInvalidPartial if
+// some party's response is wrong.
+func CombinePartialProofs(hash crypto.Hash, stmt *Statement, partials []*PartialProof) (*Proof, error) {
+	commitments := make([]*Commitment, len(partials))
+	for i, pp := range partials {
+		if pp == nil || pp.H == nil || pp.Z == nil || pp.R == nil {
+			return nil, ErrIncompleteProof
+		}
+		commitments[i] = pp.Commitment
+	}
+	c, err := JointChallenge(hash, stmt, commitments)
+	if err != nil {
+		return nil, err
+	}
+
+	curve := stmt.G.Curve
+	N := curve.Params().N
+	h := &Point{Curve: curve, X: new(big.Int), Y: new(big.Int)}
+	z := &Point{Curve: curve, X: new(big.Int), Y: new(big.Int)}
+	r := new(big.Int)
+	for _, pp := range partials {
+		share := &Statement{G: stmt.G, H: pp.H, M: stmt.M, Z: pp.Z}
+		if !share.VerifyResponse(pp.Commitment, c, pp.R) {
+			return nil, ErrInvalidPartial
+		}
+		h = h.Add(pp.H).(*Point)
+		z = z.Add(pp.Z).(*Point)
+		r.Add(r, pp.R)
+	}
+	if !h.equal(stmt.H) || !z.equal(stmt.Z) {
+		return nil, ErrShareMismatch
+	}
+
+	proof := newProof(hash, stmt, nil)
+	proof.C, proof.R = c, r.Mod(r, N)
+	return proof, nil
+}
+
+// AggregatePublicKeys adds up the parties' public keys h_1 + ... + h_n into
+// the joint key. A verifier of a threshold proof should build the joint
+// statement's H, and Z likewise, with it from the keys it knows to belong
+// to the parties, rather than accept an aggregate key from the prover. It
+// fails with ErrInconsistentCurves if the keys aren't all on one curve,
+// with ErrPointOffCurve if any key is invalid, and with ErrIdentityPoint if
+// they sum to the identity.
+func AggregatePublicKeys(keys []*Point) (*Point, error) {
+	if len(keys) == 0 {
+		return nil, ErrIncompleteProof
+	}
+	for _, key := range keys {
+		if key == nil || key.Curve == nil || key.X == nil || key.Y == nil {
+			return nil, ErrIncompleteProof
+		}
+		if !SameCurve(key.Curve, keys[0].Curve) {
+			return nil, ErrInconsistentCurves
+		}
+		if !key.IsOnCurve() {
+			return nil, ErrPointOffCurve
+		}
+	}
+	sum := &Point{Curve: keys[0].Curve, X: new(big.Int), Y: new(big.Int)}
+	for _, key := range keys {
+		sum = sum.Add(key).(*Point)
+	}
+	if sum.IsIdentity() {
+		return nil, ErrIdentityPoint
+	}
+	return sum, nil
+}
@@ -0,0 +1,131 @@
+package dleq
+
+import (
+	"crypto"
+	"crypto/elliptic"
+	"math/big"
+	"testing"
+)
+
+// testShares splits a fresh witness into two additive shares and returns the
+// joint statement along with each party's share statement.
+func testShares(t *testing.T) (stmt *Statement, x1, x2 *big.Int, h1, z1, h2, z2 *Point) {
+	curve := elliptic.P256()
+	G, H1, M, Z1, x1 := testStatement(t, curve)
+	_, _, _, _, x2 = testStatement(t, curve)
+	h2, err := PublicKey(G, x2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	z2, err = PublicKey(M, x2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stmt = &Statement{G: G, H: H1.Add(h2).(*Point), M: M, Z: Z1.Add(z2).(*Point)}
+	return stmt, x1, x2, H1, Z1, h2, z2
+}
+
+func TestThresholdProof(t *testing.T) {
+	stmt, x1, x2, h1, z1, h2, z2 := testShares(t)
+	alice := NewProver(crypto.SHA256, x1)
+	bob := NewProver(crypto.SHA256, x2)
+
+	cm1, st1, err := alice.Commit(stmt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cm2, st2, err := bob.Commit(stmt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := JointChallenge(crypto.SHA256, stmt, []*Commitment{cm1, cm2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r1, err := alice.Finalize(st1, c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r2, err := bob.Finalize(st2, c)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	partials := []*PartialProof{
+		{H: h1, Z: z1, Commitment: cm1, R: r1},
+		{H: h2, Z: z2, Commitment: cm2, R: r2},
+	}
+	proof, err := CombinePartialProofs(crypto.SHA256, stmt, partials)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !proof.Verify() {
+		t.Fatal("combined proof was invalid")
+	}
+
+	// A wrong response is pinned on the party, not just on the result.
+	partials[1].R = new(big.Int).Add(r2, big.NewInt(1))
+	if _, err := CombinePartialProofs(crypto.SHA256, stmt, partials); err != ErrInvalidPartial {
+		t.Fatalf("expected ErrInvalidPartial, got %v", err)
+	}
+
+	// Shares of some other key don't prove this statement.
+	partials[1].R = r2
+	partials[1].H = h1
+	if _, err := CombinePartialProofs(crypto.SHA256, stmt, partials); err == nil {
+		t.Fatal("combined partial proofs of the wrong shares")
+	}
+}
+
+func TestAggregatePublicKeys(t *testing.T) {
+	stmt, x1, x2, h1, z1, h2, z2 := testShares(t)
+	x := new(big.Int).Add(x1, x2)
+	x.Mod(x, stmt.G.Curve.Params().N)
+	proof, err := NewProof(crypto.SHA256, stmt.G, stmt.H, stmt.M, stmt.Z, x)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	verify := func(hs, zs []*Point) bool {
+		h, err := AggregatePublicKeys(hs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		z, err := AggregatePublicKeys(zs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		aggregated := *proof
+		aggregated.H, aggregated.Z = h, z
+		return aggregated.Verify()
+	}
+	if !verify([]*Point{h1, h2}, []*Point{z1, z2}) {
+		t.Fatal("proof was invalid over the aggregated keys")
+	}
+	if !verify([]*Point{h2, h1}, []*Point{z2, z1}) {
+		t.Fatal("aggregation depended on the order of the keys")
+	}
+	_, h3, _, z3, _ := testStatement(t, stmt.G.Curve)
+	if verify([]*Point{h1, h3}, []*Point{z1, z3}) {
+		t.Fatal("proof verified over different keys")
+	}
+	if verify([]*Point{h1}, []*Point{z1}) {
+		t.Fatal("proof verified over one party's key")
+	}
+
+	neg := &Point{Curve: h1.Curve, X: h1.X, Y: new(big.Int).Sub(h1.Curve.Params().P, h1.Y)}
+	for _, tc := range []struct {
+		keys []*Point
+		err  error
+	}{
+		{nil, ErrIncompleteProof},
+		{[]*Point{h1, nil}, ErrIncompleteProof},
+		{[]*Point{h1, neg}, ErrIdentityPoint},
+		{[]*Point{h1, {Curve: h1.Curve, X: big.NewInt(1), Y: big.NewInt(1)}}, ErrPointOffCurve},
+		{[]*Point{h1, basePoint(elliptic.P384())}, ErrInconsistentCurves},
+	} {
+		if _, err := AggregatePublicKeys(tc.keys); err != tc.err {
+			t.Errorf("got %v, expected %v", err, tc.err)
+		}
+	}
+}
@@ -0,0 +1,25 @@
+package dleq
+
+// A Verifiable is a proof that carries everything needed to check it, so
+// that proofs of different kinds can go through one verification path.
+// Proof, MinimalProof, BatchProof, GroupProof, ConjunctiveProof and
+// SharedChallengeProof are Verifiable. A DetachedProof is not, since the
+// statement has to be handed back to it.
+type Verifiable interface {
+	// Verify reports whether the proof is valid.
+	Verify() bool
+
+	// VerifyError is nil for a valid proof. Otherwise it is ErrInvalidProof
+	// for a well-formed proof that doesn't verify, or the error naming
+	// what is malformed about it.
+	VerifyError() error
+}
+
+var (
+	_ Verifiable = (*Proof)(nil)
+	_ Verifiable = (*MinimalProof)(nil)
+	_ Verifiable = (*BatchProof)(nil)
+	_ Verifiable = (*GroupProof)(nil)
+	_ Verifiable = (*ConjunctiveProof)(nil)
+	_ Verifiable = (*SharedChallengeProof)(nil)
+)
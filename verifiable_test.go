@@ -0,0 +1,102 @@
+package dleq
+
+import (
+	"crypto"
+	"crypto/elliptic"
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func TestVerifiableMixedProofs(t *testing.T) {
+	G, H, M, Z, x := testStatement(t, elliptic.P256())
+	proof, err := NewProof(crypto.SHA256, G, H, M, Z, x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	minimal, err := NewMinimalProof(crypto.SHA256, G, H, M, Z, x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bG, bH, k, tokens, signed := testIssuance(t, 3)
+	batch, err := NewBatchProof(crypto.SHA256, bG, bH, tokens, signed, k)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rG := Ristretto255.Generator()
+	rM := rG.ScalarMul(big.NewInt(7))
+	group, err := NewGroupProof(crypto.SHA256, Ristretto255, rG, rG.ScalarMul(x), rM, rM.ScalarMul(x), x)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	proofs := []Verifiable{proof, minimal, batch, group}
+	for i, v := range proofs {
+		if !v.Verify() {
+			t.Errorf("proof %d (%T) was invalid", i, v)
+		}
+		if err := v.VerifyError(); err != nil {
+			t.Errorf("proof %d (%T): %v", i, v, err)
+		}
+	}
+
+	// Break each proof's response; all of them should then report
+	// ErrInvalidProof rather than a malformed input.
+	one := big.NewInt(1)
+	badProof := *proof
+	badProof.R = new(big.Int).Add(proof.R, one)
+	badMinimal := *minimal
+	badMinimal.R = new(big.Int).Add(minimal.R, one)
+	badInner := *batch.P
+	badInner.R = new(big.Int).Add(batch.P.R, one)
+	badBatch := *batch
+	badBatch.P = &badInner
+	badGroup := *group
+	badGroup.R = new(big.Int).Add(group.R, one)
+
+	for i, v := range []Verifiable{&badProof, &badMinimal, &badBatch, &badGroup} {
+		if v.Verify() {
+			t.Errorf("broken proof %d (%T) verified", i, v)
+		}
+		if err := v.VerifyError(); err != ErrInvalidProof {
+			t.Errorf("broken proof %d (%T): expected ErrInvalidProof, got %v", i, v, err)
+		}
+	}
+}
+
+func TestVerifyErrorReasons(t *testing.T) {
+	G, H, M, Z, x := testStatement(t, elliptic.P256())
+	proof, err := NewProof(crypto.SHA256, G, H, M, Z, x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, _, _, _, _ := testStatement(t, elliptic.P384())
+
+	incomplete := *proof
+	incomplete.R = nil
+	mixed := *proof
+	mixed.M = other
+	unreduced := *proof
+	unreduced.R = new(big.Int).Add(proof.R, elliptic.P256().Params().N)
+	strict := *proof
+	strict.H, strict.opts.RequireDistinct = G, true
+
+	for _, tc := range []struct {
+		name string
+		pr   *Proof
+		err  error
+	}{
+		{"incomplete", &incomplete, ErrIncompleteProof},
+		{"mixed curves", &mixed, ErrInconsistentCurves},
+		{"unreduced response", &unreduced, ErrScalarRange},
+		{"repeated point", &strict, ErrRepeatedPoint},
+	} {
+		err := tc.pr.VerifyError()
+		if !errors.Is(err, tc.err) {
+			t.Errorf("%s: expected %v, got %v", tc.name, tc.err, err)
+		}
+		if !IsValidationError(err) {
+			t.Errorf("%s: %v is not a validation error", tc.name, err)
+		}
+	}
+}
@@ -0,0 +1,20 @@
+package dleq
+
+import (
+	"crypto"
+)
+
+// VerifyVRF treats the proof as a VRF proof, with M the hashed VRF input and
+// Z = xM the evaluation, and verifies it. Only if the proof is valid does it
+// return the VRF output H(M || Z) over the uncompressed encodings; otherwise
+// the output is nil. Getting both from one call means the output can't be
+// used ahead of the check.
+func (pr *Proof) VerifyVRF(hash crypto.Hash) (output []byte, ok bool) {
+	if !hash.Available() || !pr.Verify() {
+		return nil, false
+	}
+	H := hash.New()
+	H.Write(pr.M.Marshal())
+	H.Write(pr.Z.Marshal())
+	return H.Sum(nil), true
+}
@@ -0,0 +1,42 @@
+package dleq
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/elliptic"
+	"crypto/sha256"
+	"testing"
+)
+
+func TestVerifyVRF(t *testing.T) {
+	G, H, M, Z, x := testStatement(t, elliptic.P256())
+	proof, err := NewProof(crypto.SHA256, G, H, M, Z, x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	output, ok := proof.VerifyVRF(crypto.SHA256)
+	if !ok {
+		t.Fatal("proof was invalid")
+	}
+	want := sha256.Sum256(append(M.Marshal(), Z.Marshal()...))
+	if !bytes.Equal(output, want[:]) {
+		t.Fatal("output is not H(M || Z)")
+	}
+}
+
+func TestVerifyVRFInvalid(t *testing.T) {
+	G, H, M, Z, x := testStatement(t, elliptic.P256())
+	proof, err := NewProof(crypto.SHA256, G, H, M, Z, x)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// An evaluation under a different key must give no output at all.
+	proof.Z = proof.Z.Add(M).(*Point)
+	if output, ok := proof.VerifyVRF(crypto.SHA256); ok || output != nil {
+		t.Fatalf("invalid proof gave (%x, %v)", output, ok)
+	}
+	if output, ok := (&Proof{}).VerifyVRF(crypto.SHA256); ok || output != nil {
+		t.Fatalf("empty proof gave (%x, %v)", output, ok)
+	}
+}